@@ -0,0 +1,87 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	endpoint "github.com/go-kit/kit/endpoint"
+	http "github.com/go-kit/kit/transport/http"
+	endpoint1 "go-poker-project/Botnaught/botnaught/pkg/endpoint"
+	http2 "go-poker-project/Botnaught/botnaught/pkg/http"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+	"io/ioutil"
+	http1 "net/http"
+	"net/url"
+	"strings"
+)
+
+// New returns a BotnaughtService backed by an HTTP server living at the
+// remote instance: a bot's address as it registers with the game server, e.g.
+// "http://localhost:7081/bots/BotNaught". Method paths are joined onto the
+// address's path, so bots mounted under a prefix are reached there.
+func New(instance string, options map[string][]http.ClientOption) (service.BotnaughtService, error) {
+	if !strings.HasPrefix(instance, "http") {
+		instance = "http://" + instance
+	}
+	u, err := url.Parse(instance)
+	if err != nil {
+		return nil, err
+	}
+	var healthEndpoint endpoint.Endpoint
+	{
+		healthEndpoint = http.NewClient("POST", copyURL(u, "/health"), encodeHTTPGenericRequest, decodeHealthResponse, options["Health"]...).Endpoint()
+	}
+
+	var actionEndpoint endpoint.Endpoint
+	{
+		actionEndpoint = http.NewClient("POST", copyURL(u, "/action"), encodeHTTPGenericRequest, decodeActionResponse, options["Action"]...).Endpoint()
+	}
+
+	return endpoint1.Endpoints{
+		ActionEndpoint: actionEndpoint,
+		HealthEndpoint: healthEndpoint,
+	}, nil
+}
+
+// EncodeHTTPGenericRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes any request to the request body. Primarily useful in a client.
+func encodeHTTPGenericRequest(_ context.Context, r *http1.Request, request interface{}) error {
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+// decodeHealthResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded health response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body.
+func decodeHealthResponse(_ context.Context, r *http1.Response) (interface{}, error) {
+	if r.StatusCode != http1.StatusOK {
+		return nil, http2.ErrorDecoder(r)
+	}
+	var resp endpoint1.HealthResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeActionResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded action response from the HTTP response body. If the response
+// has a non-200 status code, we will interpret that as an error and attempt to
+// decode the specific error message from the response body.
+func decodeActionResponse(_ context.Context, r *http1.Response) (interface{}, error) {
+	if r.StatusCode != http1.StatusOK {
+		return nil, http2.ErrorDecoder(r)
+	}
+	var resp endpoint1.ActionResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+func copyURL(base *url.URL, path string) (next *url.URL) {
+	n := *base
+	n.Path = strings.TrimSuffix(base.Path, "/") + path
+	return &n
+}
@@ -0,0 +1,121 @@
+package arena
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strings"
+
+	log "github.com/go-kit/kit/log"
+	arena "go-poker-project/Botnaught/botnaught/pkg/arena"
+	config "go-poker-project/Botnaught/botnaught/pkg/config"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+var fs = flag.NewFlagSet("arena", flag.ExitOnError)
+var defaults = arena.DefaultConfig()
+var hands = fs.Int("hands", defaults.Hands, "Number of hands to play, or of deals with -duplicate")
+var seed = fs.Int64("seed", defaults.Seed, "Seed of the first deal; hand n uses seed+n")
+var smallBlind = fs.Int("small-blind", defaults.SmallBlind, "Small blind")
+var bigBlind = fs.Int("big-blind", defaults.BigBlind, "Big blind")
+var stack = fs.Int("stack", defaults.Stack, "Every seat's stack at the start of every hand")
+var configFile = fs.String("config", "", "Configuration file whose strategy section and bots are used")
+var duplicate = fs.Bool("duplicate", false, "Replay every deal with the entrants moved round the table, each getting every seat's cards")
+var aivat = fs.Bool("aivat", false, "Also report win rates with the luck of the turn and river taken out")
+var jsonOutput = fs.Bool("json", false, "Write the report as JSON")
+var keepLogs = fs.Bool("decision-logs", false, "Let the bots write their decision logs")
+var bots botFlags
+
+func init() {
+	fs.Var(&bots, "bot", "An entrant as name=strategy[,param=value...]; repeat for each entrant")
+}
+
+// botFlags collects repeated -bot flags.
+type botFlags []service.BotDefinition
+
+func (b *botFlags) String() string {
+	names := make([]string, len(*b))
+	for i, def := range *b {
+		names[i] = def.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func (b *botFlags) Set(spec string) error {
+	def, err := service.ParseBotDefinition(spec)
+	if err != nil {
+		return err
+	}
+	*b = append(*b, def)
+	return nil
+}
+
+// Run plays the entrants given on the command line, or listed in the
+// configuration file, against each other and prints the report.
+func Run(args []string) {
+	fs.Parse(args)
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+
+	cfg := config.Default()
+	cfgFlags := flag.NewFlagSet("config", flag.ContinueOnError)
+	cfg.RegisterFlags(cfgFlags)
+	if err := cfg.Load(cfgFlags, *configFile); err != nil {
+		logger.Log("during", "LoadConfig", "err", err)
+		os.Exit(1)
+	}
+	defs := []service.BotDefinition(bots)
+	if len(defs) == 0 {
+		defs = cfg.Bots
+	}
+	if err := service.Validate(defs); err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
+
+	entrants, err := Entrants(defs, cfg.Strategy, *keepLogs)
+	if err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
+	report, err := arena.Run(context.Background(), arena.Config{
+		Hands:      *hands,
+		Seed:       *seed,
+		SmallBlind: *smallBlind,
+		BigBlind:   *bigBlind,
+		Stack:      *stack,
+		Duplicate:  *duplicate,
+		AIVAT:      *aivat,
+	}, entrants)
+	if err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
+	if *jsonOutput {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
+}
+
+// Entrants builds the bots in defs, silencing their decision logs unless
+// keepLogs is set.
+func Entrants(defs []service.BotDefinition, base service.BetParams, keepLogs bool) ([]arena.Entrant, error) {
+	entrants := make([]arena.Entrant, 0, len(defs))
+	for _, def := range defs {
+		bot, err := service.NewBot(def, base, nil)
+		if err != nil {
+			return nil, err
+		}
+		if bot.Log != nil && !keepLogs {
+			bot.Log.Discard()
+		}
+		entrants = append(entrants, arena.Entrant{Name: def.Name, Bot: bot.Service})
+	}
+	return entrants, nil
+}
@@ -0,0 +1,69 @@
+package bucket
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/go-kit/kit/log"
+	bucket "go-poker-project/Botnaught/botnaught/pkg/bucket"
+)
+
+var fs = flag.NewFlagSet("bucket", flag.ExitOnError)
+var defaults = bucket.DefaultConfig()
+var buckets = fs.String("buckets", joinInts(defaults.Buckets[:]), "Buckets on the preflop, flop, turn and river, comma separated")
+var bins = fs.Int("bins", defaults.Bins, "Bins of each equity histogram")
+var rollouts = fs.Int("rollouts", defaults.Rollouts, "Runouts sampled for each histogram")
+var opponents = fs.Int("opponents", defaults.Opponents, "Opponent hands each runout is played against")
+var hands = fs.Int("hands", defaults.Hands, "Random states clustered on each street after the flop")
+var iterations = fs.Int("iterations", defaults.Iterations, "Most k-means iterations on each street")
+var seed = fs.Int64("seed", defaults.Seed, "Seed of the sampled states")
+var workers = fs.Int("workers", 0, "Histograms computed at once; 0 for one per CPU")
+var out = fs.String("out", "buckets.bin", "Write the bucket table here")
+
+func joinInts(xs []int) string {
+	s := make([]string, len(xs))
+	for i, x := range xs {
+		s[i] = strconv.Itoa(x)
+	}
+	return strings.Join(s, ",")
+}
+
+// Run builds a bucket table and writes it out.
+func Run(args []string) {
+	fs.Parse(args)
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+
+	cfg := defaults
+	fields := strings.Split(*buckets, ",")
+	if len(fields) != bucket.NumStreets {
+		logger.Log("err", "-buckets wants four counts: preflop, flop, turn and river")
+		os.Exit(1)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			logger.Log("err", "-buckets: "+err.Error())
+			os.Exit(1)
+		}
+		cfg.Buckets[i] = n
+	}
+	cfg.Bins, cfg.Rollouts, cfg.Opponents = *bins, *rollouts, *opponents
+	cfg.Hands, cfg.Iterations, cfg.Seed, cfg.Workers = *hands, *iterations, *seed, *workers
+
+	table, err := bucket.Build(cfg, func(street, states int) {
+		logger.Log("street", street, "states", states)
+	})
+	if err != nil {
+		logger.Log("during", "Build", "err", err)
+		os.Exit(1)
+	}
+	if err := table.Save(*out); err != nil {
+		logger.Log("during", "SaveTable", "err", err)
+		os.Exit(1)
+	}
+	logger.Log("table", *out)
+}
@@ -0,0 +1,57 @@
+package cfr
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/go-kit/kit/log"
+	cfr "go-poker-project/Botnaught/botnaught/pkg/cfr"
+)
+
+var fs = flag.NewFlagSet("cfr", flag.ExitOnError)
+var defaults = cfr.DefaultAbstraction()
+var iterations = fs.Int("iterations", 1000000, "MCCFR iterations to run")
+var buckets = fs.Int("buckets", defaults.Buckets, "Hand strength buckets on each street")
+var stack = fs.Int("stack", defaults.Stack, "Starting stack, in small blinds")
+var maxRaises = fs.Int("max-raises", defaults.MaxRaises, "Bets and raises allowed on each street")
+var seed = fs.Int64("seed", 1, "Seed of the sampled deals")
+var out = fs.String("out", "cfr.gob", "Write the strategy table here, loadable with -cfr-table")
+
+// Run computes a strategy table for heads-up hold'em and writes it out.
+// Interrupting it writes the table as it stands.
+func Run(args []string) {
+	fs.Parse(args)
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+
+	abs := cfr.Abstraction{Buckets: *buckets, Stack: *stack, MaxRaises: *maxRaises}
+	if abs.Buckets < 1 || abs.Stack <= 2 || abs.MaxRaises < 0 {
+		logger.Log("err", "need at least one bucket, a stack above the big blind and no negative raise limit")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		<-c
+		cancel()
+	}()
+
+	every := *iterations / 100
+	table := cfr.Train(ctx, abs, *iterations, *seed, func(done, infoSets int) {
+		if every < 1000 || done%every < 1000 {
+			logger.Log("iterations", done, "info_sets", infoSets)
+		}
+	})
+	if err := table.Save(*out); err != nil {
+		logger.Log("during", "SaveTable", "err", err)
+		os.Exit(1)
+	}
+	logger.Log("iterations", table.Iterations, "info_sets", len(table.Strategy), "table", *out)
+}
@@ -0,0 +1,87 @@
+package gameserver
+
+import (
+	"context"
+	"flag"
+	"net"
+	http1 "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/go-kit/kit/log"
+	gameserver "go-poker-project/Botnaught/botnaught/pkg/gameserver"
+)
+
+var fs = flag.NewFlagSet("gameserver", flag.ExitOnError)
+var defaults = gameserver.DefaultConfig()
+var addr = fs.String("addr", ":8888", "Listen address for /register and /results")
+var players = fs.Int("players", defaults.Players, "Bots that must register before play starts")
+var hands = fs.Int("hands", defaults.Hands, "Hands to play; 0 plays until interrupted")
+var seed = fs.Int64("seed", defaults.Seed, "Seed of the first deal; hand n uses seed+n")
+var smallBlind = fs.Int("small-blind", defaults.SmallBlind, "Small blind")
+var bigBlind = fs.Int("big-blind", defaults.BigBlind, "Big blind")
+var stack = fs.Int("stack", defaults.Stack, "Every seat's stack at the start of every hand")
+var actionTimeout = fs.Duration("action-timeout", defaults.ActionTimeout, "Time a bot has to act before it checks or folds")
+var pause = fs.Duration("pause", defaults.Pause, "Wait between hands")
+var history = fs.Int("history", defaults.History, "Hands kept for /results")
+var resultsFile = fs.String("results", "", "Append every hand to this file as a line of JSON")
+
+// Run serves registrations and plays the registered bots against each other
+// until the hands are done or the process is interrupted.
+func Run(args []string) {
+	fs.Parse(args)
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+
+	server := gameserver.New(gameserver.Config{
+		Players:       *players,
+		Hands:         *hands,
+		Seed:          *seed,
+		SmallBlind:    *smallBlind,
+		BigBlind:      *bigBlind,
+		Stack:         *stack,
+		ActionTimeout: *actionTimeout,
+		Pause:         *pause,
+		History:       *history,
+	}, logger)
+	if *resultsFile != "" {
+		f, err := os.OpenFile(*resultsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			logger.Log("during", "OpenResults", "err", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		server.Record = f
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		logger.Log("transport", "HTTP", "during", "Listen", "err", err)
+		os.Exit(1)
+	}
+	defer listener.Close()
+	go func() {
+		logger.Log("transport", "HTTP", "addr", *addr)
+		http1.Serve(listener, server.Handler())
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		<-c
+		cancel()
+	}()
+
+	err = server.Run(ctx)
+	for _, s := range server.Results().Standings {
+		logger.Log("bot", s.Name, "hands", s.Hands, "chips", s.Chips, "timeouts", s.Timeouts, "errors", s.Errors)
+	}
+	if err != nil && err != context.Canceled {
+		logger.Log("exit", err)
+		os.Exit(1)
+	}
+}
@@ -1,7 +1,35 @@
 package main
 
-import service "go-poker-project/Botnaught/botnaught/cmd/service"
+import (
+	"os"
+
+	arena "go-poker-project/Botnaught/botnaught/cmd/arena"
+	bucket "go-poker-project/Botnaught/botnaught/cmd/bucket"
+	cfr "go-poker-project/Botnaught/botnaught/cmd/cfr"
+	gameserver "go-poker-project/Botnaught/botnaught/cmd/gameserver"
+	service "go-poker-project/Botnaught/botnaught/cmd/service"
+	tune "go-poker-project/Botnaught/botnaught/cmd/tune"
+)
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "arena":
+			arena.Run(os.Args[2:])
+			return
+		case "bucket":
+			bucket.Run(os.Args[2:])
+			return
+		case "cfr":
+			cfr.Run(os.Args[2:])
+			return
+		case "gameserver":
+			gameserver.Run(os.Args[2:])
+			return
+		case "tune":
+			tune.Run(os.Args[2:])
+			return
+		}
+	}
 	service.Run()
 }
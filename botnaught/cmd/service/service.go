@@ -8,11 +8,15 @@ import (
 	endpoint1 "github.com/go-kit/kit/endpoint"
 	client1 "github.com/gSchool/golang-curriculum-c-6/server/client/http"
 	log "github.com/go-kit/kit/log"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	lightsteptracergo "github.com/lightstep/lightstep-tracer-go"
 	group "github.com/oklog/oklog/pkg/group"
 	opentracinggo "github.com/opentracing/opentracing-go"
 	zipkingoopentracing "github.com/openzipkin/zipkin-go-opentracing"
+	prometheus "github.com/prometheus/client_golang/prometheus"
 	promhttp "github.com/prometheus/client_golang/prometheus/promhttp"
+	cfr "go-poker-project/Botnaught/botnaught/pkg/cfr"
+	config "go-poker-project/Botnaught/botnaught/pkg/config"
 	endpoint "go-poker-project/Botnaught/botnaught/pkg/endpoint"
 	http "github.com/go-kit/kit/transport/http"
 	http2 "go-poker-project/Botnaught/botnaught/pkg/http"
@@ -21,6 +25,7 @@ import (
 	http1 "net/http"
 	"os"
 	"os/signal"
+	"strings"
 	appdash "sourcegraph.com/sourcegraph/appdash"
 	opentracing "sourcegraph.com/sourcegraph/appdash/opentracing"
 	"syscall"
@@ -31,21 +36,49 @@ var logger log.Logger
 
 // Define our flags. Your service probably won't need to bind listeners for
 // all* supported transports, but we do it here for demonstration purposes.
+// Every setting lives in cfg; see the config package for how files and
+// BOTNAUGHT_* environment variables override the defaults.
 var fs = flag.NewFlagSet("botnaught", flag.ExitOnError)
-var debugAddr = fs.String("debug.addr", ":7080", "Debug and metrics listen address")
-var httpAddr = fs.String("http-addr", ":7081", "HTTP listen address")
-var grpcAddr = fs.String("grpc-addr", ":8082", "gRPC listen address")
-var thriftAddr = fs.String("thrift-addr", ":8083", "Thrift listen address")
-var thriftProtocol = fs.String("thrift-protocol", "binary", "binary, compact, json, simplejson")
-var thriftBuffer = fs.Int("thrift-buffer", 0, "0 for unbuffered")
-var thriftFramed = fs.Bool("thrift-framed", false, "true to enable framing")
-var zipkinURL = fs.String("zipkin-url", "", "Enable Zipkin tracing via a collector URL e.g. http://localhost:9411/api/v1/spans")
-var lightstepToken = fs.String("lightstep-token", "", "Enable LightStep tracing via a LightStep access token")
-var appdashAddr = fs.String("appdash-addr", "", "Enable Appdash tracing via an Appdash server host:port")
-
-var pokerBotName = fs.String("botname", "BotNaught", "The name of the poker bot that will be registered")
-
-func Run() {
+var configFile = fs.String("config", "", "YAML or JSON configuration file")
+var printConfig = fs.Bool("print-config", false, "Print the effective configuration, tokens redacted, and exit")
+var cfg = config.Default()
+
+func init() {
+	cfg.RegisterFlags(fs)
+}
+
+// duration is shared by every hosted bot; each bot's endpoints observe it
+// with their own "bot" label.
+var duration = kitprometheus.NewSummaryFrom(prometheus.SummaryOpts{
+	Namespace: "botnaught",
+	Name:      "request_duration_seconds",
+	Help:      "Request duration in seconds.",
+}, []string{"bot", "method", "success"})
+
+// cacheLookups counts every bot's decision cache lookups by result.
+var cacheLookups = kitprometheus.NewCounterFrom(prometheus.CounterOpts{
+	Namespace: "botnaught",
+	Name:      "decision_cache_lookups_total",
+	Help:      "Decision cache lookups, by result: hit or miss.",
+}, []string{"bot", "result"})
+
+// hostedBot is a bot served by this process, mounted under its own path
+// prefix on the shared HTTP listener.
+type hostedBot struct {
+	*service.Bot
+	endpoints endpoint.Endpoints
+	reg       *service.Registration
+}
+
+// prefix is the path the bot's endpoints are mounted under, e.g. /bots/BotNaught.
+func (b hostedBot) prefix() string {
+	return "/bots/" + b.Definition.Name
+}
+
+// Run serves every bot in bots from the same HTTP listener and registers
+// each of them with the game server. With no bots, those in the
+// configuration are hosted.
+func Run(bots ...service.BotDefinition) {
 	fs.Parse(os.Args[1:])
 
 	// Create a single logger, which we'll use and give to other components.
@@ -53,11 +86,23 @@ func Run() {
 	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
 	logger = log.With(logger, "caller", log.DefaultCaller)
 
+	if err := cfg.Load(fs, *configFile); err != nil {
+		logger.Log("during", "LoadConfig", "err", err)
+		os.Exit(1)
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			logger.Log("err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	//  Determine which tracer to use. We'll pass the tracer to all the
 	// components that use it, as a dependency
-	if *zipkinURL != "" {
-		logger.Log("tracer", "Zipkin", "URL", *zipkinURL)
-		collector, err := zipkingoopentracing.NewHTTPCollector(*zipkinURL)
+	if cfg.Transport.ZipkinURL != "" {
+		logger.Log("tracer", "Zipkin", "URL", cfg.Transport.ZipkinURL)
+		collector, err := zipkingoopentracing.NewHTTPCollector(cfg.Transport.ZipkinURL)
 		if err != nil {
 			logger.Log("err", err)
 			os.Exit(1)
@@ -69,13 +114,13 @@ func Run() {
 			logger.Log("err", err)
 			os.Exit(1)
 		}
-	} else if *lightstepToken != "" {
+	} else if cfg.Transport.LightstepToken != "" {
 		logger.Log("tracer", "LightStep")
-		tracer = lightsteptracergo.NewTracer(lightsteptracergo.Options{AccessToken: *lightstepToken})
+		tracer = lightsteptracergo.NewTracer(lightsteptracergo.Options{AccessToken: cfg.Transport.LightstepToken})
 		defer lightsteptracergo.FlushLightStepTracer(tracer)
-	} else if *appdashAddr != "" {
-		logger.Log("tracer", "Appdash", "addr", *appdashAddr)
-		collector := appdash.NewRemoteCollector(*appdashAddr)
+	} else if cfg.Transport.AppdashAddr != "" {
+		logger.Log("tracer", "Appdash", "addr", cfg.Transport.AppdashAddr)
+		collector := appdash.NewRemoteCollector(cfg.Transport.AppdashAddr)
 		tracer = opentracing.NewTracer(collector)
 		defer collector.Close()
 	} else {
@@ -83,72 +128,223 @@ func Run() {
 		tracer = opentracinggo.GlobalTracer()
 	}
 
-	svc := service.New(getServiceMiddleware(logger))
-	eps := endpoint.New(svc, getEndpointMiddleware(logger))
-	g := createService(eps)
-	initMetricsEndpoint(g)
-	initCancelInterrupt(g)
+	if len(bots) == 0 {
+		var err error
+		if bots, err = cfg.BotDefinitions(); err != nil {
+			logger.Log("err", err)
+			os.Exit(1)
+		}
+	}
+	if err := service.Validate(bots); err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
 
-	reg := func() {
-		time.Sleep(time.Second * 2)
-		svc, err := client1.New("http://localhost:8888", map[string][]http.ClientOption{})
-		if err != nil {
-			panic(err)
+	base := cfg.Strategy
+	if cfg.StrategyFile != "" {
+		var err error
+		if base, err = config.ReadParams(cfg.StrategyFile, cfg.Strategy); err != nil {
+			logger.Log("during", "ReadParams", "err", err)
+			os.Exit(1)
 		}
+	}
+	var table *cfr.Table
+	if cfg.CFRTable != "" {
+		var err error
+		if table, err = cfr.LoadTable(cfg.CFRTable); err != nil {
+			logger.Log("during", "LoadTable", "err", err)
+			os.Exit(1)
+		}
+	}
 
-		ctx, _ := context.WithTimeout(context.Background(), 1000*time.Millisecond)
-		err = svc.Register(ctx, "http://localhost"+*httpAddr, *pokerBotName)
+	hosted := make([]hostedBot, 0, len(bots))
+	for _, def := range bots {
+		reg := &service.Registration{}
+		bot, err := service.NewBot(def, base, getServiceMiddleware(logger, reg))
 		if err != nil {
-			panic(err)
+			logger.Log("bot", def.Name, "err", err)
+			os.Exit(1)
+		}
+		if table != nil && bot.Policy != nil {
+			bot.Policy.Set(table)
+		}
+		if bot.Cache != nil {
+			bot.Cache.Configure(cfg.DecisionCache.Size, cfg.DecisionCache.TTL.Duration, cacheLookups.With("bot", def.Name))
+		}
+		if bot.Showdowns != nil {
+			path := strings.ToLower(def.Name) + "-showdowns.jsonl"
+			skipped, err := bot.Showdowns.Open(path)
+			if err != nil {
+				logger.Log("bot", def.Name, "during", "OpenShowdowns", "err", err)
+				os.Exit(1)
+			}
+			for _, err := range skipped {
+				logger.Log("bot", def.Name, "during", "OpenShowdowns", "skipped", err)
+			}
 		}
+		eps := endpoint.New(bot.Service, getEndpointMiddleware(logger, def.Name))
+		hosted = append(hosted, hostedBot{Bot: bot, endpoints: eps, reg: reg})
+	}
+	g := createService(hosted)
+	initMetricsEndpoint(g, hosted)
+	initCancelInterrupt(g)
+	if cfg.StrategyFile != "" {
+		initParamsWatcher(g, hosted)
+	}
+
+	for _, b := range hosted {
+		go register(b)
 	}
-	go reg()
 
 	logger.Log("exit", g.Run())
 
 }
-func initHttpHandler(endpoints endpoint.Endpoints, g *group.Group) {
+
+// register announces the bot to the game server once the listener is up,
+// retrying until it succeeds. Each outcome is recorded for /health/ready.
+func register(b hostedBot) {
+	time.Sleep(cfg.Registration.Delay.Duration)
+	for {
+		err := registerOnce(b)
+		b.reg.Set(err)
+		if err == nil {
+			logger.Log("bot", b.Definition.Name, "registered", b.prefix())
+			return
+		}
+		logger.Log("bot", b.Definition.Name, "during", "Register", "err", err)
+		time.Sleep(cfg.Registration.Retry.Duration)
+	}
+}
+func registerOnce(b hostedBot) error {
+	svc, err := client1.New(cfg.Registration.ServerURL, map[string][]http.ClientOption{})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Registration.Timeout.Duration)
+	defer cancel()
+	return svc.Register(ctx, cfg.Registration.CallbackHost+cfg.Transport.HTTPAddr+b.prefix(), b.Definition.Name)
+}
+func initHttpHandler(bots []hostedBot, g *group.Group) {
 	options := defaultHttpOptions(logger, tracer)
 	// Add your http options here
 
-	httpHandler := http2.NewHTTPHandler(endpoints, options)
-	httpListener, err := net.Listen("tcp", *httpAddr)
+	httpHandler := http1.NewServeMux()
+	for _, b := range bots {
+		httpHandler.Handle(b.prefix()+"/", http1.StripPrefix(b.prefix(), http2.NewHTTPHandler(b.endpoints, options)))
+	}
+	// A lone bot is also served from the root, where it always used to live.
+	if len(bots) == 1 {
+		httpHandler.Handle("/", http2.NewHTTPHandler(bots[0].endpoints, options))
+	}
+	httpListener, err := net.Listen("tcp", cfg.Transport.HTTPAddr)
 	if err != nil {
 		logger.Log("transport", "HTTP", "during", "Listen", "err", err)
 	}
 	g.Add(func() error {
-		logger.Log("transport", "HTTP", "addr", *httpAddr)
+		logger.Log("transport", "HTTP", "addr", cfg.Transport.HTTPAddr)
 		return http1.Serve(httpListener, httpHandler)
 	}, func(error) {
 		httpListener.Close()
 	})
 
 }
-func getServiceMiddleware(logger log.Logger) (mw []service.Middleware) {
+func getServiceMiddleware(logger log.Logger, reg *service.Registration) (mw []service.Middleware) {
 	mw = []service.Middleware{}
 	// Append your middleware here
+	mw = append(mw, service.RegistrationMiddleware(reg))
 
 	return
 }
-func getEndpointMiddleware(logger log.Logger) (mw map[string][]endpoint1.Middleware) {
+func getEndpointMiddleware(logger log.Logger, botName string) (mw map[string][]endpoint1.Middleware) {
 	mw = map[string][]endpoint1.Middleware{}
 	// Add you endpoint middleware here
+	for _, method := range []string{"Health", "Action"} {
+		mw[method] = append(mw[method], endpoint.InstrumentingMiddleware(duration.With("bot", botName, "method", method)))
+	}
 
 	return
 }
-func initMetricsEndpoint(g *group.Group) {
+func initMetricsEndpoint(g *group.Group, bots []hostedBot) {
 	http1.DefaultServeMux.Handle("/metrics", promhttp.Handler())
-	debugListener, err := net.Listen("tcp", *debugAddr)
+	if cfg.Admin.Token != "" {
+		http1.DefaultServeMux.Handle("/admin/", http2.NewAdminHandler("/admin/bots", cfg.Admin.Token, serviceBots(bots)))
+	} else {
+		logger.Log("transport", "debug/HTTP", "admin", "disabled, no admin.token")
+	}
+	debugListener, err := net.Listen("tcp", cfg.Transport.DebugAddr)
 	if err != nil {
 		logger.Log("transport", "debug/HTTP", "during", "Listen", "err", err)
 	}
 	g.Add(func() error {
-		logger.Log("transport", "debug/HTTP", "addr", *debugAddr)
+		logger.Log("transport", "debug/HTTP", "addr", cfg.Transport.DebugAddr)
 		return http1.Serve(debugListener, http1.DefaultServeMux)
 	}, func(error) {
 		debugListener.Close()
 	})
 }
+
+// initParamsWatcher reloads the strategy file whenever it changes and swaps
+// the new parameters into every tunable bot, keeping each bot's own overrides.
+func initParamsWatcher(g *group.Group, bots []hostedBot) {
+	stop := make(chan struct{})
+	g.Add(func() error {
+		var modified time.Time
+		if info, err := os.Stat(cfg.StrategyFile); err == nil {
+			modified = info.ModTime()
+		}
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return nil
+			}
+			info, err := os.Stat(cfg.StrategyFile)
+			if err != nil || !info.ModTime().After(modified) {
+				continue
+			}
+			modified = info.ModTime()
+			base, err := config.ReadParams(cfg.StrategyFile, cfg.Strategy)
+			if err != nil {
+				logger.Log("during", "ReadParams", "err", err)
+				continue
+			}
+			for _, b := range bots {
+				if b.Params == nil {
+					continue
+				}
+				stored, err := storeParams(b, base)
+				if err != nil {
+					logger.Log("bot", b.Definition.Name, "during", "ReloadParams", "err", err)
+					continue
+				}
+				logger.Log("bot", b.Definition.Name, "params", "reloaded", "version", stored.Version)
+			}
+		}
+	}, func(error) {
+		close(stop)
+	})
+}
+
+// storeParams makes base, with the bot's own overrides, its current parameters.
+func storeParams(b hostedBot, base service.BetParams) (service.VersionedParams, error) {
+	params, err := base.Apply(b.Definition.Params)
+	if err != nil {
+		return service.VersionedParams{}, err
+	}
+	return b.Params.Store(params)
+}
+
+// serviceBots returns the service.Bot behind each hosted bot.
+func serviceBots(bots []hostedBot) []*service.Bot {
+	s := make([]*service.Bot, len(bots))
+	for i, b := range bots {
+		s[i] = b.Bot
+	}
+	return s
+}
 func initCancelInterrupt(g *group.Group) {
 	cancelInterrupt := make(chan struct{})
 	g.Add(func() error {
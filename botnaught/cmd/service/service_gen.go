@@ -8,13 +8,12 @@ import (
 	http "github.com/go-kit/kit/transport/http"
 	group "github.com/oklog/oklog/pkg/group"
 	opentracinggo "github.com/opentracing/opentracing-go"
-	endpoint "go-poker-project/Botnaught/botnaught/pkg/endpoint"
 	http1 "go-poker-project/Botnaught/botnaught/pkg/http"
 )
 
-func createService(endpoints endpoint.Endpoints) (g *group.Group) {
+func createService(bots []hostedBot) (g *group.Group) {
 	g = &group.Group{}
-	initHttpHandler(endpoints, g)
+	initHttpHandler(bots, g)
 	return g
 }
 func defaultHttpOptions(logger log.Logger, tracer opentracinggo.Tracer) map[string][]http.ServerOption {
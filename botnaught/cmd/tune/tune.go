@@ -0,0 +1,112 @@
+package tune
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	log "github.com/go-kit/kit/log"
+	config "go-poker-project/Botnaught/botnaught/pkg/config"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+	tune "go-poker-project/Botnaught/botnaught/pkg/tune"
+)
+
+var fs = flag.NewFlagSet("tune", flag.ExitOnError)
+var defaults = tune.DefaultConfig()
+var generations = fs.Int("generations", defaults.Generations, "Generations to breed")
+var population = fs.Int("population", defaults.Population, "Candidates in each generation")
+var elite = fs.Int("elite", defaults.Elite, "Fittest candidates carried into the next generation unchanged")
+var mutation = fs.Float64("mutation", defaults.Mutation, "Chance each parameter of a child is mutated")
+var sigma = fs.Float64("sigma", defaults.Sigma, "Size of a mutation, as a fraction of the parameter's range")
+var seed = fs.Int64("seed", defaults.Seed, "Seed of the search and of the deals")
+var hands = fs.Int("hands", defaults.Match.Hands, "Duplicate deals played against each opponent per evaluation")
+var workers = fs.Int("workers", 0, "Candidates evaluated at once; 0 for one per CPU")
+var configFile = fs.String("config", "", "Configuration file whose strategy section the search starts from")
+var out = fs.String("out", "tuned.yaml", "Write a configuration with only the best parameters found, as its strategy section, here")
+var opponents opponentFlags
+
+func init() {
+	fs.Var(&opponents, "opponent", "An opponent as name=strategy[,param=value...]; repeat for each. Defaults to call, tight-passive, loose-aggressive and equity bots")
+}
+
+// opponentFlags collects repeated -opponent flags.
+type opponentFlags []service.BotDefinition
+
+func (o *opponentFlags) String() string {
+	names := make([]string, len(*o))
+	for i, def := range *o {
+		names[i] = def.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func (o *opponentFlags) Set(spec string) error {
+	def, err := service.ParseBotDefinition(spec)
+	if err != nil {
+		return err
+	}
+	*o = append(*o, def)
+	return nil
+}
+
+// Run searches for Bet parameters that beat the opponents and writes them
+// out as the strategy section of a configuration file, loadable with
+// -config. The rest of the configuration, secrets included, stays out.
+func Run(args []string) {
+	fs.Parse(args)
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+
+	cfg := config.Default()
+	cfgFlags := flag.NewFlagSet("config", flag.ContinueOnError)
+	cfg.RegisterFlags(cfgFlags)
+	if err := cfg.Load(cfgFlags, *configFile); err != nil {
+		logger.Log("during", "LoadConfig", "err", err)
+		os.Exit(1)
+	}
+
+	search := defaults
+	search.Generations, search.Population, search.Elite = *generations, *population, *elite
+	search.Mutation, search.Sigma, search.Seed = *mutation, *sigma, *seed
+	search.Match.Hands, search.Workers = *hands, *workers
+	if len(opponents) > 0 {
+		search.Opponents = opponents
+	}
+	if err := service.Validate(append([]service.BotDefinition{{Name: "Candidate", Strategy: "basic"}}, search.Opponents...)); err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		<-c
+		cancel()
+	}()
+
+	res, err := tune.Run(ctx, search, cfg.Strategy, func(g tune.Generation) {
+		logger.Log("generation", g.Number, "best_bb_per_100", g.Best, "mean_bb_per_100", g.Mean)
+	})
+	if err != nil {
+		logger.Log("during", "Tune", "err", err)
+		os.Exit(1)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		logger.Log("during", "WriteConfig", "err", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if err := config.PrintStrategy(f, res.Best); err != nil {
+		logger.Log("during", "WriteConfig", "err", err)
+		os.Exit(1)
+	}
+	logger.Log("best_bb_per_100", res.Fitness, "config", *out)
+}
@@ -0,0 +1,127 @@
+package arena
+
+import (
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	sim "go-poker-project/Botnaught/botnaught/pkg/sim"
+)
+
+// luck returns the chips the turn and river cards moved towards each seat.
+// Subtracting it from a result leaves an unbiased estimate of the seat's
+// winnings with much of the variance of the deal removed.
+//
+// This is the chance-node half of AIVAT (Burch et al., 2018): every chance
+// event is scored as the value of the state it produced less the expected
+// value over all the cards that could have come, which averages to zero
+// whatever the value function. Ours values a seat at its equity share of the
+// pot, the hole cards of every seat known. The flop is left uncorrected as
+// its exact expectation costs too much to enumerate every hand; duplicate
+// deals take care of the hole cards.
+func luck(res sim.Result) []float64 {
+	n := len(res.Hole)
+	adjust := make([]float64, n)
+
+	// foldedOn[seat] is the street the seat folded on, NumStreets if never.
+	foldedOn := make([]sim.Street, n)
+	for i := range foldedOn {
+		foldedOn[i] = sim.NumStreets
+	}
+	for _, a := range res.Actions {
+		if a.Verb == "fold" {
+			foldedOn[a.Seat] = a.Street
+		}
+	}
+
+	deck := cards.Without(res.Hole...)
+	for street := sim.Turn; street <= res.EndStreet && street < sim.NumStreets; street++ {
+		var live []int
+		pot := 0
+		for seat := range res.Hole {
+			if foldedOn[seat] >= street {
+				live = append(live, seat)
+			}
+			for s := sim.Preflop; s < street; s++ {
+				pot += res.Invested[seat][s]
+			}
+		}
+		if len(live) < 2 {
+			break
+		}
+		holes := make([][]poker.Card, len(live))
+		for i, seat := range live {
+			holes[i] = res.Hole[seat]
+		}
+		shown := boardSize(street)
+		before := equity(holes, res.Board[:shown-1], deck)
+		after := equity(holes, res.Board[:shown], deck)
+		for i, seat := range live {
+			adjust[seat] += float64(pot) * (after[i] - before[i])
+		}
+	}
+	return adjust
+}
+
+// boardSize is the number of board cards out once street has been dealt.
+func boardSize(street sim.Street) int {
+	switch street {
+	case sim.Preflop:
+		return 0
+	case sim.Flop:
+		return 3
+	case sim.Turn:
+		return 4
+	}
+	return 5
+}
+
+// equity returns each hand's share of the pot over every way the board can
+// be completed from the cards in deck not already on it, ties split.
+func equity(holes [][]poker.Card, board, deck []poker.Card) []float64 {
+	onBoard := map[poker.Card]bool{}
+	for _, c := range board {
+		onBoard[c] = true
+	}
+	var remaining []poker.Card
+	for _, c := range deck {
+		if !onBoard[c] {
+			remaining = append(remaining, c)
+		}
+	}
+
+	shares := make([]float64, len(holes))
+	boards := 0
+	full := make([]poker.Card, 5)
+	copy(full, board)
+	ranks := make([]int32, len(holes))
+	var complete func(next, from int)
+	complete = func(next, from int) {
+		if next == 5 {
+			boards++
+			best, winners := int32(0), 0
+			for i, hole := range holes {
+				ranks[i] = poker.Evaluate(append(append([]poker.Card(nil), hole...), full...))
+				switch {
+				case winners == 0 || ranks[i] < best:
+					best, winners = ranks[i], 1
+				case ranks[i] == best:
+					winners++
+				}
+			}
+			for i := range holes {
+				if ranks[i] == best {
+					shares[i] += 1 / float64(winners)
+				}
+			}
+			return
+		}
+		for i := from; i < len(remaining); i++ {
+			full[next] = remaining[i]
+			complete(next+1, i+1)
+		}
+	}
+	complete(len(board), 0)
+	for i := range shares {
+		shares[i] /= float64(boards)
+	}
+	return shares
+}
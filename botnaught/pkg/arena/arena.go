@@ -0,0 +1,192 @@
+// Package arena plays bots against each other in-process and reports how
+// they did. Deals are seeded from Config.Seed, so two runs with the same seed
+// deal the same cards and a change to a bot can be compared hand for hand.
+package arena
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+	sim "go-poker-project/Botnaught/botnaught/pkg/sim"
+)
+
+// Entrant is a bot taking part.
+type Entrant struct {
+	Name string
+	Bot  service.BotnaughtService
+}
+
+// Config describes a match.
+type Config struct {
+	// Hands is the number of hands to play.
+	Hands      int
+	Seed       int64
+	SmallBlind int
+	BigBlind   int
+	// Stack is every seat's stack at the start of every hand.
+	Stack int
+	// Duplicate replays every deal once per seating, the entrants moved
+	// round the table each time while the cards and the button stay put, so
+	// each entrant is dealt every seat's cards. Hands then counts deals.
+	Duplicate bool
+	// AIVAT takes the luck of the turn and river cards out of each result
+	// and reports the adjusted win rates alongside the raw ones.
+	AIVAT bool
+}
+
+// DefaultConfig returns 1,000 hands at 1/2 blinds with 100 big blind stacks.
+func DefaultConfig() Config {
+	return Config{Hands: 1000, Seed: 1, SmallBlind: 1, BigBlind: 2, Stack: 200}
+}
+
+// Run plays cfg.Hands hands between the entrants, all seated at one table.
+// The button moves every hand and the entrants move one seat every time it
+// has gone round the table, so each of them plays from every seat. In a
+// duplicate match every deal is instead played once from each seating. The
+// hands make up one game, and the bots are shown the latest ones before
+// each, so that they can learn their opponents' tendencies.
+func Run(ctx context.Context, cfg Config, entrants []Entrant) (*Report, error) {
+	n := len(entrants)
+	if n < 2 {
+		return nil, errors.New("arena: need at least two entrants")
+	}
+	if cfg.Hands <= 0 {
+		return nil, fmt.Errorf("arena: %d hands", cfg.Hands)
+	}
+
+	tallies := make([]*tally, n)
+	for i := range tallies {
+		tallies[i] = &tally{}
+	}
+	played := 0
+	var history sim.History
+	for deal := 0; deal < cfg.Hands; deal++ {
+		rotations := []int{deal / n}
+		if cfg.Duplicate {
+			rotations = rotations[:0]
+			for r := 0; r < n; r++ {
+				rotations = append(rotations, r)
+			}
+		}
+
+		// The entrants' results on this deal, summed over its replays.
+		bb := make([]float64, n)
+		adjusted := make([]float64, n)
+		for _, rotation := range rotations {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// seating[seat] is the entrant in that seat.
+			seating := make([]int, n)
+			seats := make([]sim.Seat, n)
+			stacks := make([]int, n)
+			for seat := range seats {
+				seating[seat] = (seat + rotation) % n
+				seats[seat] = sim.Seat{Name: entrants[seating[seat]].Name, Bot: entrants[seating[seat]].Bot}
+				stacks[seat] = cfg.Stack
+			}
+			played++
+			res, err := sim.Play(ctx, sim.Hand{
+				GameID:        "arena-" + strconv.FormatInt(cfg.Seed, 10),
+				Number:        played,
+				Seats:         seats,
+				Stacks:        stacks,
+				Button:        deal % n,
+				SmallBlind:    cfg.SmallBlind,
+				BigBlind:      cfg.BigBlind,
+				StartingStack: cfg.Stack,
+				Seed:          cfg.Seed + int64(deal),
+				Log:           history.Log(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			history.Add(res.Log)
+			var adjust []float64
+			if cfg.AIVAT {
+				adjust = luck(res)
+			}
+			for seat, entrant := range seating {
+				tallies[entrant].add(res, seat)
+				bb[entrant] += float64(res.Deltas[seat]) / float64(cfg.BigBlind)
+				if adjust != nil {
+					adjusted[entrant] += (float64(res.Deltas[seat]) - adjust[seat]) / float64(cfg.BigBlind)
+				}
+			}
+		}
+		// A deal's replays are one sample: averaging them first is what
+		// cancels the luck of the cards.
+		for i, t := range tallies {
+			t.bb.add(bb[i] / float64(len(rotations)))
+			t.adjusted.add(adjusted[i] / float64(len(rotations)))
+		}
+	}
+
+	report := &Report{Hands: played, Seed: cfg.Seed, BigBlind: cfg.BigBlind, Duplicate: cfg.Duplicate, AIVAT: cfg.AIVAT}
+	for i, e := range entrants {
+		report.Bots = append(report.Bots, tallies[i].stats(e.Name, cfg))
+	}
+	return report, nil
+}
+
+// tally accumulates one entrant's results.
+type tally struct {
+	hands       int
+	chips       int
+	bb          sample
+	adjusted    sample
+	vpip, pfr   int
+	streetChips [sim.NumStreets]int
+	errors      int
+}
+
+func (t *tally) add(res sim.Result, seat int) {
+	t.hands++
+	t.chips += res.Deltas[seat]
+	t.errors += res.SeatErrors[seat]
+
+	voluntary, raised := false, false
+	for _, a := range res.Actions {
+		if a.Seat != seat || a.Street != sim.Preflop {
+			continue
+		}
+		voluntary = voluntary || a.Verb == "call" || a.Verb == "raise"
+		raised = raised || a.Verb == "raise"
+	}
+	if voluntary {
+		t.vpip++
+	}
+	if raised {
+		t.pfr++
+	}
+
+	for street, invested := range res.Invested[seat] {
+		t.streetChips[street] -= invested
+	}
+	t.streetChips[res.EndStreet] += res.Won[seat]
+}
+
+func (t *tally) stats(name string, cfg Config) BotStats {
+	s := BotStats{
+		Name:           name,
+		Hands:          t.hands,
+		Chips:          t.chips,
+		BBPer100:       t.bb.mean() * 100,
+		CI95:           t.bb.ci95() * 100,
+		VPIP:           float64(t.vpip) / float64(t.hands),
+		PFR:            float64(t.pfr) / float64(t.hands),
+		StreetBBPer100: map[string]float64{},
+		Errors:         t.errors,
+	}
+	if cfg.AIVAT {
+		s.AIVATBBPer100 = t.adjusted.mean() * 100
+		s.AIVATCI95 = t.adjusted.ci95() * 100
+	}
+	for street, chips := range t.streetChips {
+		s.StreetBBPer100[sim.Street(street).String()] = float64(chips) / float64(cfg.BigBlind) / float64(t.hands) * 100
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+package arena
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+// fixed always answers with the same action.
+type fixed game.Action
+
+func (f fixed) Health(ctx context.Context) (service.HealthStatus, error) {
+	return service.HealthStatus{Live: true, Ready: true}, nil
+}
+func (f fixed) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	return game.Action(f), nil
+}
+
+func TestRun(t *testing.T) {
+	entrants := []Entrant{
+		{Name: "Caller", Bot: fixed{SelectedAction: "call"}},
+		{Name: "Folder", Bot: fixed{SelectedAction: "fold"}},
+		{Name: "Raiser", Bot: fixed{SelectedAction: "raise", Value: 6}},
+	}
+	cfg := DefaultConfig()
+	cfg.Hands = 300
+	report, err := Run(context.Background(), cfg, entrants)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chips := 0
+	for _, b := range report.Bots {
+		if b.Hands != cfg.Hands {
+			t.Errorf("%s played %d hands, want %d", b.Name, b.Hands, cfg.Hands)
+		}
+		chips += b.Chips
+	}
+	if chips != 0 {
+		t.Errorf("chips sum to %d, want 0", chips)
+	}
+
+	byName := map[string]BotStats{}
+	for _, b := range report.Bots {
+		byName[b.Name] = b
+	}
+	if s := byName["Folder"]; s.VPIP != 0 || s.PFR != 0 || s.Chips >= 0 {
+		t.Errorf("Folder: VPIP %v, PFR %v, chips %d; want 0, 0 and a loss", s.VPIP, s.PFR, s.Chips)
+	}
+	if s := byName["Raiser"]; s.PFR != 1 {
+		t.Errorf("Raiser: PFR %v, want 1", s.PFR)
+	}
+	if s := byName["Caller"]; s.PFR != 0 || s.VPIP == 0 {
+		t.Errorf("Caller: VPIP %v, PFR %v; want some and 0", s.VPIP, s.PFR)
+	}
+
+	again, err := Run(context.Background(), cfg, entrants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range report.Bots {
+		if again.Bots[i].Chips != report.Bots[i].Chips {
+			t.Errorf("%s: %d chips on the rerun, %d before", report.Bots[i].Name, again.Bots[i].Chips, report.Bots[i].Chips)
+		}
+	}
+}
+
+// broken always fails.
+type broken struct{ fixed }
+
+func (broken) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	return game.Action{}, errors.New("broken")
+}
+
+func TestErrorsAreTheirs(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Hands = 20
+	report, err := Run(context.Background(), cfg, []Entrant{
+		{Name: "Broken", Bot: broken{}},
+		{Name: "Caller", Bot: fixed{SelectedAction: "call"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, c := report.Bots[0], report.Bots[1]; b.Errors == 0 || c.Errors != 0 {
+		t.Errorf("Broken made %d errors and Caller %d; want some and none", b.Errors, c.Errors)
+	}
+}
+
+// recorder remembers the games its bot is asked to act in.
+type recorder struct {
+	service.BotnaughtService
+	games []game.Game
+}
+
+func (r *recorder) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	r.games = append(r.games, g)
+	return r.BotnaughtService.Action(ctx, g)
+}
+
+func TestLearnsOpponents(t *testing.T) {
+	params := service.DefaultBetParams()
+	params.Bluff, params.Slowplay, params.SizeMix = 0, 0, 0
+	newBot := func() *service.Bot {
+		bot, err := service.NewBot(service.BotDefinition{Name: "Basic", Strategy: "basic"}, params, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bot.Log.Discard()
+		return bot
+	}
+	played, fresh := newBot(), newBot()
+	rec := &recorder{BotnaughtService: played.Service}
+	cfg := DefaultConfig()
+	cfg.Hands = 150
+	if _, err := Run(context.Background(), cfg, []Entrant{{Name: "Basic", Bot: rec}, {Name: "Caller", Bot: fixed{SelectedAction: "call"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if s := played.Showdowns.Stats("Caller"); s.Showdowns == 0 {
+		t.Errorf("Caller's showdowns = %+v, want some", s)
+	}
+
+	// A bot that has watched the caller plays some spot differently from
+	// one seeing it for the first time
+	changed := false
+	for _, g := range rec.games[len(rec.games)/2:] {
+		want, err := played.Service.Action(context.Background(), g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := len(g.HandLog) - 1; i >= 0; i-- {
+			if strings.HasPrefix(g.HandLog[i], "-- hand ") {
+				g.HandLog = g.HandLog[i:]
+				break
+			}
+		}
+		got, err := fresh.Service.Action(context.Background(), g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changed = changed || got != want
+	}
+	if !changed {
+		t.Error("what the bot learnt of the caller changed none of its decisions")
+	}
+}
+
+func TestRunNeedsTwo(t *testing.T) {
+	if _, err := Run(context.Background(), DefaultConfig(), []Entrant{{Name: "Alone", Bot: fixed{}}}); err == nil {
+		t.Error("want an error for one entrant")
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	caller := fixed{SelectedAction: "call"}
+	cfg := DefaultConfig()
+	cfg.Hands, cfg.Duplicate = 200, true
+	report, err := Run(context.Background(), cfg, []Entrant{{Name: "A", Bot: caller}, {Name: "B", Bot: caller}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Hands != 400 {
+		t.Errorf("played %d hands, want 400", report.Hands)
+	}
+	// The same bot on both sides of every deal breaks exactly even.
+	for _, b := range report.Bots {
+		if b.Chips != 0 || b.CI95 != 0 {
+			t.Errorf("%s: %d chips ±%v, want 0 ±0", b.Name, b.Chips, b.CI95)
+		}
+	}
+}
+
+func TestAIVAT(t *testing.T) {
+	caller := fixed{SelectedAction: "call"}
+	cfg := DefaultConfig()
+	cfg.Hands, cfg.AIVAT = 300, true
+	report, err := Run(context.Background(), cfg, []Entrant{{Name: "A", Bot: caller}, {Name: "B", Bot: caller}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := 0.0
+	for _, b := range report.Bots {
+		if b.AIVATCI95 >= b.CI95 {
+			t.Errorf("%s: AIVAT interval ±%v is no tighter than ±%v", b.Name, b.AIVATCI95, b.CI95)
+		}
+		sum += b.AIVATBBPer100
+	}
+	if sum > 1e-9 || sum < -1e-9 {
+		t.Errorf("adjusted win rates sum to %v, want 0", sum)
+	}
+}
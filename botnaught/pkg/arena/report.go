@@ -0,0 +1,107 @@
+package arena
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+// Report is the outcome of a match.
+type Report struct {
+	Hands     int        `json:"hands"`
+	Seed      int64      `json:"seed"`
+	BigBlind  int        `json:"big_blind"`
+	Duplicate bool       `json:"duplicate"`
+	AIVAT     bool       `json:"aivat"`
+	Bots      []BotStats `json:"bots"`
+}
+
+// BotStats is how one entrant did. Win rates are in big blinds per 100
+// hands; CI95 is the half-width of the 95% confidence interval of BBPer100.
+// In a duplicate match the interval is taken over deals, not hands.
+type BotStats struct {
+	Name     string  `json:"name"`
+	Hands    int     `json:"hands"`
+	Chips    int     `json:"chips"`
+	BBPer100 float64 `json:"bb_per_100"`
+	CI95     float64 `json:"ci95"`
+	// AIVATBBPer100 and AIVATCI95 are the win rate and its interval with
+	// the luck of the turn and river taken out, when the match asked for it.
+	AIVATBBPer100 float64 `json:"aivat_bb_per_100,omitempty"`
+	AIVATCI95     float64 `json:"aivat_ci95,omitempty"`
+	// VPIP is the share of hands the bot put chips in voluntarily before
+	// the flop and PFR the share it raised before the flop.
+	VPIP float64 `json:"vpip"`
+	PFR  float64 `json:"pfr"`
+	// StreetBBPer100 splits BBPer100 by street: chips put in on a street
+	// count against it and the pot counts for the street the hand ended on.
+	StreetBBPer100 map[string]float64 `json:"street_bb_per_100"`
+	Errors         int                `json:"errors"`
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report as a table.
+func (r *Report) WriteText(w io.Writer) error {
+	mode := ""
+	if r.Duplicate {
+		mode = ", duplicate"
+	}
+	fmt.Fprintf(w, "%d hands, seed %d, big blind %d%s\n\n", r.Hands, r.Seed, r.BigBlind, mode)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	header := "bot\tbb/100\t±95%\t"
+	if r.AIVAT {
+		header += "AIVAT\t±95%\t"
+	}
+	fmt.Fprintln(tw, header+"chips\tVPIP\tPFR\tpreflop\tflop\tturn\triver\terrors\t")
+	for _, b := range r.Bots {
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t", b.Name, b.BBPer100, b.CI95)
+		if r.AIVAT {
+			fmt.Fprintf(tw, "%.2f\t%.2f\t", b.AIVATBBPer100, b.AIVATCI95)
+		}
+		fmt.Fprintf(tw, "%d\t%.1f%%\t%.1f%%\t%.2f\t%.2f\t%.2f\t%.2f\t%d\t\n",
+			b.Chips, b.VPIP*100, b.PFR*100,
+			b.StreetBBPer100["preflop"], b.StreetBBPer100["flop"], b.StreetBBPer100["turn"], b.StreetBBPer100["river"],
+			b.Errors)
+	}
+	return tw.Flush()
+}
+
+// sample accumulates the mean and variance of a series of results.
+type sample struct {
+	n          int
+	sum, sumSq float64
+}
+
+func (s *sample) add(x float64) {
+	s.n++
+	s.sum += x
+	s.sumSq += x * x
+}
+
+func (s *sample) mean() float64 {
+	if s.n == 0 {
+		return 0
+	}
+	return s.sum / float64(s.n)
+}
+
+// ci95 is the half-width of the normal 95% confidence interval of the mean.
+func (s *sample) ci95() float64 {
+	if s.n < 2 {
+		return 0
+	}
+	mean := s.mean()
+	variance := (s.sumSq - float64(s.n)*mean*mean) / float64(s.n-1)
+	if variance < 0 {
+		variance = 0
+	}
+	return 1.96 * math.Sqrt(variance/float64(s.n))
+}
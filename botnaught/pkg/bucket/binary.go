@@ -0,0 +1,146 @@
+package bucket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// magic starts every table file, with the format version.
+var magic = [4]byte{'B', 'K', 'T', '2'}
+
+// The file format, all integers little-endian:
+//
+//	magic, then bins, rollouts and opponents as uint32
+//	for each street:
+//		buckets as uint32, then each centroid as bins float32s
+//		entries as uint32, then each entry's key as uint64 and bucket as a byte
+
+// Write encodes t in the binary table format.
+func (t *Table) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	put := func(v interface{}) {
+		binary.Write(bw, binary.LittleEndian, v)
+	}
+	put(magic)
+	put([]uint32{uint32(t.Bins), uint32(t.Rollouts), uint32(t.Opponents)})
+	for _, st := range t.streets {
+		put(uint32(len(st.centroids)))
+		for _, c := range st.centroids {
+			f := make([]float32, len(c))
+			for i, v := range c {
+				f[i] = float32(v)
+			}
+			put(f)
+		}
+		put(uint32(len(st.entries)))
+		buf := make([]byte, 9)
+		for _, e := range st.entries {
+			binary.LittleEndian.PutUint64(buf, e.key)
+			buf[8] = e.bucket
+			bw.Write(buf)
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadTable decodes a table written by Write.
+func ReadTable(r io.Reader) (*Table, error) {
+	br := bufio.NewReader(r)
+	var m [4]byte
+	if err := binary.Read(br, binary.LittleEndian, &m); err != nil {
+		return nil, err
+	}
+	if m != magic {
+		return nil, errors.New("bucket: not a bucket table")
+	}
+	var header [3]uint32
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	t := &Table{Bins: int(header[0]), Rollouts: int(header[1]), Opponents: int(header[2])}
+	if t.Bins < 2 || t.Bins > 1000 {
+		return nil, fmt.Errorf("bucket: bad bin count %d", t.Bins)
+	}
+	for s := range t.streets {
+		var k uint32
+		if err := binary.Read(br, binary.LittleEndian, &k); err != nil {
+			return nil, err
+		}
+		if k < 1 || k > 256 {
+			return nil, fmt.Errorf("bucket: street %d: bad bucket count %d", s, k)
+		}
+		st := street{centroids: make([][]float64, k)}
+		f := make([]float32, t.Bins)
+		for c := range st.centroids {
+			if err := binary.Read(br, binary.LittleEndian, f); err != nil {
+				return nil, err
+			}
+			st.centroids[c] = make([]float64, t.Bins)
+			for i, v := range f {
+				if math.IsNaN(float64(v)) {
+					return nil, fmt.Errorf("bucket: street %d: bad centroid", s)
+				}
+				st.centroids[c][i] = float64(v)
+			}
+		}
+		var n uint32
+		if err := binary.Read(br, binary.LittleEndian, &n); err != nil {
+			return nil, err
+		}
+		// The count comes from the file: grow to it as entries are read
+		// rather than trusting it with one allocation.
+		st.entries = make([]entry, 0, minEntries(n))
+		buf := make([]byte, 9)
+		for i := uint32(0); i < n; i++ {
+			if _, err := io.ReadFull(br, buf); err != nil {
+				return nil, err
+			}
+			e := entry{key: binary.LittleEndian.Uint64(buf), bucket: buf[8]}
+			if int(e.bucket) >= len(st.centroids) || (i > 0 && e.key <= st.entries[i-1].key) {
+				return nil, fmt.Errorf("bucket: street %d: bad entry %d", s, i)
+			}
+			st.entries = append(st.entries, e)
+		}
+		t.streets[s] = st
+	}
+	return t, nil
+}
+
+// Save writes t to the file at path.
+func (t *Table) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadTable reads the table saved at path.
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTable(f)
+}
+
+// preallocEntries is the most entries Read allocates room for before reading
+// them.
+const preallocEntries = 1 << 16
+
+func minEntries(n uint32) int {
+	if n > preallocEntries {
+		return preallocEntries
+	}
+	return int(n)
+}
@@ -0,0 +1,300 @@
+// Package bucket groups hole cards and boards into buckets of hands that
+// play alike, one set of buckets per street. A hand is described by its
+// equity histogram: how its equity against a random hand at the river is
+// spread over the ways the board can still run out, so a flush draw and a
+// weak pair with the same average equity land apart. Histograms are
+// clustered by k-means under the earth mover's distance. Buckets are
+// numbered from the lowest average equity up.
+//
+// A Table caches the bucket of every state seen while building it, keyed by
+// the suit-isomorphic hash of the cards from package iso, and is saved in a
+// compact binary format. States it hasn't seen are bucketed by computing
+// their histogram and finding the nearest centroid, which takes tens of
+// milliseconds, and remembered up to a limit.
+package bucket
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	iso "go-poker-project/Botnaught/botnaught/pkg/iso"
+)
+
+// Streets, indexing Config.Buckets and a Table's streets.
+const (
+	Preflop = iota
+	Flop
+	Turn
+	River
+	NumStreets
+)
+
+// StreetOf returns the street a board of the given size is on.
+func StreetOf(board []poker.Card) int {
+	if len(board) < 3 {
+		return Preflop
+	}
+	return len(board) - 2
+}
+
+// Config describes how a table is built.
+type Config struct {
+	// Buckets is the number of buckets on each street, at most 256.
+	Buckets [NumStreets]int
+	// Bins is the number of histogram bins between equity 0 and 1.
+	Bins int
+	// Rollouts is the number of runouts sampled for a histogram and
+	// Opponents the number of opponent hands each runout is played against.
+	Rollouts  int
+	Opponents int
+	// Hands is the number of random states clustered on each street after
+	// the flop. Every preflop hand is clustered.
+	Hands      int
+	Iterations int
+	Seed       int64
+	// Workers is how many histograms are computed at once; 0 means one per
+	// CPU.
+	Workers int
+}
+
+// DefaultConfig returns 10 preflop buckets and 32 on each later street,
+// clustered from 20,000 states a street.
+func DefaultConfig() Config {
+	return Config{
+		Buckets:    [NumStreets]int{10, 32, 32, 32},
+		Bins:       20,
+		Rollouts:   50,
+		Opponents:  60,
+		Hands:      20000,
+		Iterations: 30,
+		Seed:       1,
+	}
+}
+
+// Validate reports settings a table can't be built with.
+func (c Config) Validate() error {
+	for street, k := range c.Buckets {
+		if k < 1 || k > 256 {
+			return fmt.Errorf("bucket: street %d: %d buckets, want 1 to 256", street, k)
+		}
+	}
+	switch {
+	case c.Bins < 2:
+		return errors.New("bucket: need at least two bins")
+	case c.Rollouts < 1 || c.Opponents < 1:
+		return errors.New("bucket: need at least one rollout against one opponent")
+	case c.Hands < 1:
+		return errors.New("bucket: need at least one hand a street")
+	}
+	return nil
+}
+
+// entry is the bucket of one canonical state.
+type entry struct {
+	key    uint64
+	bucket uint8
+}
+
+// street holds one street's centroids, ordered by mean equity, and the
+// states bucketed while building, sorted by key.
+type street struct {
+	centroids [][]float64
+	entries   []entry
+}
+
+// Table maps states to buckets. It is safe for concurrent use.
+type Table struct {
+	Bins      int
+	Rollouts  int
+	Opponents int
+	streets   [NumStreets]street
+	// computed remembers the buckets of states not in the table.
+	computed computedBuckets
+}
+
+// ComputedLimit is how many buckets of states not in its table a Table
+// remembers; beyond it the least recently used are forgotten.
+const ComputedLimit = 1 << 16
+
+// computedBuckets is a least recently used cache of buckets by state key.
+// The zero value is empty.
+type computedBuckets struct {
+	mu    sync.Mutex
+	order *list.List
+	items map[uint64]*list.Element
+}
+
+type computedBucket struct {
+	key    uint64
+	bucket int
+}
+
+func (c *computedBuckets) get(key uint64) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return 0, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(computedBucket).bucket, true
+}
+
+func (c *computedBuckets) put(key uint64, bucket int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.items == nil {
+		c.order, c.items = list.New(), map[uint64]*list.Element{}
+	}
+	if _, ok := c.items[key]; ok {
+		return
+	}
+	c.items[key] = c.order.PushFront(computedBucket{key, bucket})
+	for c.order.Len() > ComputedLimit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(computedBucket).key)
+	}
+}
+
+// Build clusters states on every street. progress, when not nil, is called
+// as each street is finished.
+func Build(cfg Config, progress func(street, states int)) (*Table, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	t := &Table{Bins: cfg.Bins, Rollouts: cfg.Rollouts, Opponents: cfg.Opponents}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	for s := Preflop; s < NumStreets; s++ {
+		var states [][2][]poker.Card
+		if s == Preflop {
+			states = preflopHands()
+		} else {
+			states = sampleStates(rng, s, cfg.Hands)
+		}
+		// Duplicates in canonical form are clustered once.
+		keys := make([]uint64, 0, len(states))
+		seen := map[uint64]bool{}
+		unique := states[:0]
+		for _, st := range states {
+			key := iso.Hash(st[0], st[1])
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+				unique = append(unique, st)
+			}
+		}
+		hists := t.histograms(unique, cfg.Workers)
+		centroids, assign := kmeans(hists, cfg.Buckets[s], cfg.Iterations, rng)
+		st := street{centroids: centroids, entries: make([]entry, len(keys))}
+		for i, key := range keys {
+			st.entries[i] = entry{key: key, bucket: uint8(assign[i])}
+		}
+		sort.Slice(st.entries, func(i, j int) bool { return st.entries[i].key < st.entries[j].key })
+		t.streets[s] = st
+		if progress != nil {
+			progress(s, len(keys))
+		}
+	}
+	return t, nil
+}
+
+// histograms computes the histogram of every state, workers at a time.
+// Each state's runouts are drawn from its own seed, so the result doesn't
+// depend on the number of workers.
+func (t *Table) histograms(states [][2][]poker.Card, workers int) [][]float64 {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	hists := make([][]float64, len(states))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				st := states[i]
+				rng := rand.New(rand.NewSource(int64(iso.Hash(st[0], st[1]))))
+				hists[i] = t.histogram(st[0], st[1], rng)
+			}
+		}()
+	}
+	for i := range states {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return hists
+}
+
+// Bucket returns the bucket of hole cards with the board so far.
+func (t *Table) Bucket(hole, board []poker.Card) int {
+	s := StreetOf(board)
+	st := &t.streets[s]
+	key := iso.Hash(hole, board)
+	i := sort.Search(len(st.entries), func(i int) bool { return st.entries[i].key >= key })
+	if i < len(st.entries) && st.entries[i].key == key {
+		return int(st.entries[i].bucket)
+	}
+	if b, ok := t.computed.get(key); ok {
+		return b
+	}
+	h := t.histogram(hole, board, rand.New(rand.NewSource(int64(key))))
+	b := nearest(h, st.centroids)
+	t.computed.put(key, b)
+	return b
+}
+
+// Buckets returns the number of buckets on a street.
+func (t *Table) Buckets(street int) int {
+	return len(t.streets[street].centroids)
+}
+
+// Strength returns the mean equity of the bucket of hole cards with the
+// board so far, between 0 and 1.
+func (t *Table) Strength(hole, board []poker.Card) float64 {
+	return mean(t.streets[StreetOf(board)].centroids[t.Bucket(hole, board)])
+}
+
+// preflopHands returns one hand of each of the 169 kinds: pairs, suited and
+// offsuit.
+func preflopHands() [][2][]poker.Card {
+	var hands [][2][]poker.Card
+	for hi := 0; hi < len(cards.Ranks); hi++ {
+		for lo := 0; lo <= hi; lo++ {
+			h := cards.Ranks[hi : hi+1]
+			l := cards.Ranks[lo : lo+1]
+			hands = append(hands, [2][]poker.Card{cards.MustParse(h + "s" + l + "h")})
+			if lo < hi {
+				hands = append(hands, [2][]poker.Card{cards.MustParse(h + "s" + l + "s")})
+			}
+		}
+	}
+	return hands
+}
+
+// sampleStates deals n random hole cards and boards for a street.
+func sampleStates(rng *rand.Rand, s, n int) [][2][]poker.Card {
+	states := make([][2][]poker.Card, n)
+	deck := cards.All()
+	for i := range states {
+		dealt := 2 + s + 2
+		for j := 0; j < dealt; j++ {
+			k := j + rng.Intn(len(deck)-j)
+			deck[j], deck[k] = deck[k], deck[j]
+		}
+		states[i] = [2][]poker.Card{
+			append([]poker.Card(nil), deck[:2]...),
+			append([]poker.Card(nil), deck[2:dealt]...),
+		}
+	}
+	return states
+}
@@ -0,0 +1,99 @@
+package bucket
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+func TestEMD(t *testing.T) {
+	a := []float64{1, 0, 0, 0}
+	b := []float64{0, 0, 0, 1}
+	c := []float64{0, 1, 0, 0}
+	if d := emd(a, a); d != 0 {
+		t.Errorf("emd(a, a) = %v", d)
+	}
+	if d := emd(a, b); d != 3 {
+		t.Errorf("emd(a, b) = %v, want 3", d)
+	}
+	if emd(a, c) >= emd(a, b) {
+		t.Error("moving mass one bin should cost less than three")
+	}
+}
+
+func TestTable(t *testing.T) {
+	cfg := Config{
+		Buckets:    [NumStreets]int{5, 6, 6, 6},
+		Bins:       10,
+		Rollouts:   20,
+		Opponents:  20,
+		Hands:      300,
+		Iterations: 10,
+		Seed:       1,
+	}
+	table, err := Build(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s := Preflop; s < NumStreets; s++ {
+		if n := table.Buckets(s); n != cfg.Buckets[s] {
+			t.Errorf("street %d: %d buckets, want %d", s, n, cfg.Buckets[s])
+		}
+	}
+
+	bucket := func(tb *Table, hole, board string) int {
+		return tb.Bucket(cards.MustParse(hole), cards.MustParse(board))
+	}
+	if aces, trash := bucket(table, "AsAh", ""), bucket(table, "7s2h", ""); aces <= trash {
+		t.Errorf("aces in bucket %d, 72o in %d", aces, trash)
+	}
+	if nuts, air := bucket(table, "AsKs", "QsJsTs2d3c"), bucket(table, "4h5d", "QsJsTs2d8c"); nuts <= air {
+		t.Errorf("royal flush in bucket %d, no pair in %d", nuts, air)
+	}
+	// States not seen while building are bucketed the same way every time.
+	if a, b := bucket(table, "9c8c", "7c6d2h"), bucket(table, "9h8h", "7h6s2c"); a != b {
+		t.Errorf("equivalent flops in buckets %d and %d", a, b)
+	}
+
+	var buf bytes.Buffer
+	if err := table.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadTable(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range [][2]string{{"AsAh", ""}, {"Td9d", "8d2c3h"}, {"KcQc", "Kd7s2h9h"}, {"6s6h", "6d7s2h9hAc"}} {
+		if a, b := bucket(table, st[0], st[1]), bucket(read, st[0], st[1]); a != b {
+			t.Errorf("%s %s: bucket %d before saving, %d after", st[0], st[1], a, b)
+		}
+	}
+	if _, err := ReadTable(bytes.NewReader([]byte("nope"))); err == nil {
+		t.Error("read a table from garbage")
+	}
+	// A truncated table claiming four billion preflop entries
+	var saved bytes.Buffer
+	table.Write(&saved)
+	count := 4 + 3*4 + 4 + cfg.Buckets[Preflop]*cfg.Bins*4
+	truncated := append([]byte(nil), saved.Bytes()[:count+4]...)
+	binary.LittleEndian.PutUint32(truncated[count:], math.MaxUint32)
+	if _, err := ReadTable(bytes.NewReader(truncated)); err == nil {
+		t.Error("read a truncated table")
+	}
+}
+
+func TestComputedLimit(t *testing.T) {
+	var c computedBuckets
+	for key := uint64(0); key <= ComputedLimit; key++ {
+		c.put(key, 1)
+	}
+	if _, ok := c.get(0); ok || c.order.Len() != ComputedLimit {
+		t.Errorf("kept %d buckets and the oldest: %v; want %d without it", c.order.Len(), ok, ComputedLimit)
+	}
+	if b, ok := c.get(ComputedLimit); !ok || b != 1 {
+		t.Errorf("newest bucket = %d, %v; want 1", b, ok)
+	}
+}
@@ -0,0 +1,182 @@
+package bucket
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// histogram returns how hole cards' equity against a random hand at the
+// river is spread over sampled runouts of the board, as fractions of the
+// runouts in each of t.Bins bins.
+func (t *Table) histogram(hole, board []poker.Card, rng *rand.Rand) []float64 {
+	h := make([]float64, t.Bins)
+	live := cards.Without(hole, board)
+	missing := 5 - len(board)
+	rollouts := t.Rollouts
+	if missing == 0 {
+		// There is only one runout; spend the samples on opponents.
+		rollouts = 1
+	}
+	opponents := t.Opponents * t.Rollouts / rollouts
+
+	mine := make([]poker.Card, 0, 7)
+	theirs := make([]poker.Card, 7)
+	for r := 0; r < rollouts; r++ {
+		for j := 0; j < missing; j++ {
+			k := j + rng.Intn(len(live)-j)
+			live[j], live[k] = live[k], live[j]
+		}
+		full := append(append([]poker.Card(nil), board...), live[:missing]...)
+		rest := live[missing:]
+		mine = append(append(mine[:0], hole...), full...)
+		score := poker.Evaluate(mine)
+
+		won := 0.0
+		copy(theirs[2:], full)
+		for o := 0; o < opponents; o++ {
+			a := rng.Intn(len(rest))
+			b := rng.Intn(len(rest) - 1)
+			if b >= a {
+				b++
+			}
+			theirs[0], theirs[1] = rest[a], rest[b]
+			switch other := poker.Evaluate(theirs); {
+			case score < other:
+				won++
+			case score == other:
+				won += 0.5
+			}
+		}
+		bin := int(won / float64(opponents) * float64(t.Bins))
+		if bin >= t.Bins {
+			bin = t.Bins - 1
+		}
+		h[bin]++
+	}
+	for i := range h {
+		h[i] /= float64(rollouts)
+	}
+	return h
+}
+
+// emd is the earth mover's distance between two histograms over the same
+// bins: the area between their cumulative distributions.
+func emd(a, b []float64) float64 {
+	d, carry := 0.0, 0.0
+	for i := range a {
+		carry += a[i] - b[i]
+		d += math.Abs(carry)
+	}
+	return d
+}
+
+// mean returns a histogram's mean equity, taking each bin at its middle.
+func mean(h []float64) float64 {
+	m := 0.0
+	for i, p := range h {
+		m += p * (float64(i) + 0.5) / float64(len(h))
+	}
+	return m
+}
+
+// nearest returns the index of the centroid closest to h.
+func nearest(h []float64, centroids [][]float64) int {
+	best, bestDist := 0, math.Inf(1)
+	for i, c := range centroids {
+		if d := emd(h, c); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// kmeans clusters points into at most k clusters under the earth mover's
+// distance, seeded by k-means++, and returns the centroids ordered by mean
+// equity with each point's cluster.
+func kmeans(points [][]float64, k, iterations int, rng *rand.Rand) ([][]float64, []int) {
+	if k > len(points) {
+		k = len(points)
+	}
+	bins := len(points[0])
+
+	// k-means++: each further centroid is a point picked with probability
+	// proportional to its squared distance from the nearest one so far.
+	centroids := [][]float64{append([]float64(nil), points[rng.Intn(len(points))]...)}
+	dist := make([]float64, len(points))
+	for len(centroids) < k {
+		total := 0.0
+		for i, p := range points {
+			d := emd(p, centroids[nearest(p, centroids)])
+			dist[i] = d * d
+			total += dist[i]
+		}
+		pick := 0
+		if total > 0 {
+			x := rng.Float64() * total
+			for pick = 0; pick < len(points)-1 && x >= dist[pick]; pick++ {
+				x -= dist[pick]
+			}
+		} else {
+			pick = rng.Intn(len(points))
+		}
+		centroids = append(centroids, append([]float64(nil), points[pick]...))
+	}
+
+	assign := make([]int, len(points))
+	for it := 0; it < iterations; it++ {
+		changed := it == 0
+		for i, p := range points {
+			if c := nearest(p, centroids); c != assign[i] {
+				assign[i] = c
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		sums := make([][]float64, k)
+		counts := make([]int, k)
+		for c := range sums {
+			sums[c] = make([]float64, bins)
+		}
+		for i, p := range points {
+			counts[assign[i]]++
+			for j, v := range p {
+				sums[assign[i]][j] += v
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				// Keep an emptied centroid where it was.
+				continue
+			}
+			for j := range sums[c] {
+				centroids[c][j] = sums[c][j] / float64(counts[c])
+			}
+		}
+	}
+	for i, p := range points {
+		assign[i] = nearest(p, centroids)
+	}
+
+	// Renumber the clusters from the weakest up.
+	order := make([]int, k)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return mean(centroids[order[i]]) < mean(centroids[order[j]]) })
+	rank := make([]int, k)
+	sorted := make([][]float64, k)
+	for r, c := range order {
+		rank[c] = r
+		sorted[r] = centroids[c]
+	}
+	for i := range assign {
+		assign[i] = rank[assign[i]]
+	}
+	return sorted, assign
+}
@@ -0,0 +1,185 @@
+// Package cards has the helpers shared by everything that reasons about
+// poker.Cards: the full deck, seeded shuffles, rank and suit lookups and a
+// quick score of hole cards.
+package cards
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	poker "github.com/chehsunliu/poker"
+)
+
+// Ranks lists the rank characters from deuce to ace; a card's Rank is its
+// index here.
+const Ranks = "23456789TJQKA"
+
+// Suits lists the suit characters; a card's Suit is its index here.
+const Suits = "shdc"
+
+var (
+	deck  [52]poker.Card
+	index = map[poker.Card]int{}
+)
+
+func init() {
+	for r := range Ranks {
+		for s := range Suits {
+			i := r*len(Suits) + s
+			deck[i] = poker.NewCard(Ranks[r:r+1] + Suits[s:s+1])
+			index[deck[i]] = i
+		}
+	}
+}
+
+// All returns the 52 cards, ordered by rank then suit.
+func All() []poker.Card {
+	all := make([]poker.Card, len(deck))
+	copy(all, deck[:])
+	return all
+}
+
+// Index returns c's position in All, from 0 (2s) to 51 (Ac).
+func Index(c poker.Card) int {
+	return index[c]
+}
+
+// FromIndex is the inverse of Index.
+func FromIndex(i int) poker.Card {
+	return deck[i]
+}
+
+// Rank returns c's rank, from 0 (deuce) to 12 (ace).
+func Rank(c poker.Card) int {
+	return index[c] / len(Suits)
+}
+
+// Suit returns c's suit, from 0 to 3 in the order of Suits.
+func Suit(c poker.Card) int {
+	return index[c] % len(Suits)
+}
+
+// Parse reads cards written like "AsKd", "As Kd" or "As,Kd".
+func Parse(s string) ([]poker.Card, error) {
+	s = strings.NewReplacer(" ", "", ",", "").Replace(s)
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("cards %q: odd number of characters", s)
+	}
+	parsed := make([]poker.Card, 0, len(s)/2)
+	for i := 0; i < len(s); i += 2 {
+		r := strings.IndexByte(Ranks, strings.ToUpper(s[i : i+1])[0])
+		su := strings.IndexByte(Suits, strings.ToLower(s[i+1 : i+2])[0])
+		if r < 0 || su < 0 {
+			return nil, fmt.Errorf("cards %q: bad card %q", s, s[i:i+2])
+		}
+		parsed = append(parsed, deck[r*len(Suits)+su])
+	}
+	return parsed, nil
+}
+
+// MustParse is Parse for cards known to be valid; it panics otherwise.
+func MustParse(s string) []poker.Card {
+	parsed, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// String writes cards separated by spaces.
+func String(cs []poker.Card) string {
+	s := make([]string, len(cs))
+	for i, c := range cs {
+		s[i] = c.String()
+	}
+	return strings.Join(s, " ")
+}
+
+// Deck is a shuffled deck that cards are drawn from in order.
+type Deck struct {
+	cards []poker.Card
+}
+
+// NewDeck returns a deck shuffled by seed; the same seed always gives the
+// same order.
+func NewDeck(seed int64) *Deck {
+	d := &Deck{cards: All()}
+	rand.New(rand.NewSource(seed)).Shuffle(len(d.cards), func(i, j int) {
+		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+	})
+	return d
+}
+
+// Draw removes and returns the next n cards.
+func (d *Deck) Draw(n int) []poker.Card {
+	drawn := d.cards[:n:n]
+	d.cards = d.cards[n:]
+	return drawn
+}
+
+// Remaining returns how many cards are left.
+func (d *Deck) Remaining() int {
+	return len(d.cards)
+}
+
+// Without returns the cards of All not in dead.
+func Without(dead ...[]poker.Card) []poker.Card {
+	var used [52]bool
+	for _, cs := range dead {
+		for _, c := range cs {
+			used[index[c]] = true
+		}
+	}
+	live := make([]poker.Card, 0, 52)
+	for i, c := range deck {
+		if !used[i] {
+			live = append(live, c)
+		}
+	}
+	return live
+}
+
+// PreflopStrength scores hole cards from 0 to 1: pairs from .5 up by rank,
+// other hands by their ranks, high card weighted double, with a bonus for
+// suited and for connected cards.
+func PreflopStrength(hole []poker.Card) float64 {
+	hi, lo := Rank(hole[0]), Rank(hole[1])
+	if lo > hi {
+		hi, lo = lo, hi
+	}
+	if hi == lo {
+		return 0.5 + 0.5*float64(hi)/12
+	}
+	s := 0.6 * float64(2*hi+lo) / 36
+	if Suit(hole[0]) == Suit(hole[1]) {
+		s += 0.1
+	}
+	if hi-lo == 1 {
+		s += 0.05
+	}
+	return s
+}
+
+// RankMask sets bit r+1 for every rank r among cs, and bit 0 for an ace so
+// that it also plays low.
+func RankMask(cs []poker.Card) uint16 {
+	var m uint16
+	for _, c := range cs {
+		r := Rank(c)
+		m |= 1 << uint(r+1)
+		if r == 12 {
+			m |= 1
+		}
+	}
+	return m
+}
+
+// Bits counts the bits set in m.
+func Bits(m uint16) int {
+	n := 0
+	for ; m != 0; m &= m - 1 {
+		n++
+	}
+	return n
+}
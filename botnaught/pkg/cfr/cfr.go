@@ -0,0 +1,377 @@
+// Package cfr computes approximate equilibrium strategies for two-player
+// zero-sum games by counterfactual regret minimization. Small games whose
+// chance nodes can be listed are solved with CFR+; games too big for that,
+// like the hold'em abstraction in this package, with external-sampling Monte
+// Carlo CFR. Everything runs on the CPU in a single goroutine.
+package cfr
+
+import (
+	"math/rand"
+)
+
+// State is a node of a game tree. States are values: Play returns a new
+// state and leaves the receiver alone.
+type State interface {
+	Terminal() bool
+	// Utility is what player wins at a terminal state.
+	Utility(player int) float64
+	Chance() bool
+	// Outcomes lists a chance node's children and their probabilities.
+	Outcomes() ([]State, []float64)
+	// Player is the player to act, 0 or 1.
+	Player() int
+	// InfoSet identifies what the player to act knows. States the player
+	// can't tell apart must share it, and have the same actions.
+	InfoSet() string
+	NumActions() int
+	Play(action int) State
+}
+
+// Sampler is implemented by chance nodes with too many outcomes to list.
+// MCCFR samples them instead; CFR can't solve games that have them.
+type Sampler interface {
+	Sample(rng *rand.Rand) State
+}
+
+// Strategy maps information sets to the probability of each action.
+type Strategy map[string][]float64
+
+// Probabilities returns the strategy at key, or a uniform one over n actions
+// when key was never reached in training.
+func (s Strategy) Probabilities(key string, n int) []float64 {
+	if p, ok := s[key]; ok && len(p) == n {
+		return p
+	}
+	p := make([]float64, n)
+	for i := range p {
+		p[i] = 1 / float64(n)
+	}
+	return p
+}
+
+// node is the regret and strategy sum of one information set. CFR gathers
+// an iteration's regrets in delta, so that every state of the information
+// set is played with the same strategy, and adds them in afterwards.
+type node struct {
+	regret      []float64
+	strategySum []float64
+	delta       []float64
+	touched     bool
+}
+
+// current is the regret-matching strategy: actions in proportion to their
+// positive regret, uniform when none is positive.
+func (n *node) current() []float64 {
+	s := make([]float64, len(n.regret))
+	total := 0.0
+	for i, r := range n.regret {
+		if r > 0 {
+			s[i] = r
+			total += r
+		}
+	}
+	for i := range s {
+		if total > 0 {
+			s[i] /= total
+		} else {
+			s[i] = 1 / float64(len(s))
+		}
+	}
+	return s
+}
+
+// Solver accumulates regrets over iterations. The zero value is not usable;
+// call NewSolver.
+type Solver struct {
+	nodes     map[string]*node
+	iteration int
+	touched   []*node
+}
+
+// NewSolver returns a solver with no regrets.
+func NewSolver() *Solver {
+	return &Solver{nodes: map[string]*node{}}
+}
+
+// InfoSets returns the number of information sets visited so far.
+func (s *Solver) InfoSets() int {
+	return len(s.nodes)
+}
+
+func (s *Solver) node(key string, actions int) *node {
+	n, ok := s.nodes[key]
+	if !ok {
+		n = &node{regret: make([]float64, actions), strategySum: make([]float64, actions)}
+		s.nodes[key] = n
+	}
+	return n
+}
+
+// Strategy returns the average strategy, which is what converges to an
+// equilibrium.
+func (s *Solver) Strategy() Strategy {
+	strategy := make(Strategy, len(s.nodes))
+	for key, n := range s.nodes {
+		total := 0.0
+		for _, v := range n.strategySum {
+			total += v
+		}
+		p := make([]float64, len(n.strategySum))
+		for i := range p {
+			if total > 0 {
+				p[i] = n.strategySum[i] / total
+			} else {
+				p[i] = 1 / float64(len(p))
+			}
+		}
+		strategy[key] = p
+	}
+	return strategy
+}
+
+// CFR runs one iteration of CFR+ from root, updating each player in turn:
+// negative regrets are dropped and later iterations weigh more in the
+// average strategy.
+func (s *Solver) CFR(root State) {
+	s.iteration++
+	for player := 0; player < 2; player++ {
+		s.cfr(root, player, 1, 1)
+		for _, n := range s.touched {
+			for a, d := range n.delta {
+				n.regret[a] += d
+				if n.regret[a] < 0 {
+					n.regret[a] = 0
+				}
+				n.delta[a] = 0
+			}
+			n.touched = false
+		}
+		s.touched = s.touched[:0]
+	}
+}
+
+// cfr returns the expected utility of st for traverser, where mine is the
+// traverser's probability of reaching st and others everyone else's,
+// chance included.
+func (s *Solver) cfr(st State, traverser int, mine, others float64) float64 {
+	switch {
+	case st.Terminal():
+		return st.Utility(traverser)
+	case st.Chance():
+		children, probs := st.Outcomes()
+		u := 0.0
+		for i, child := range children {
+			u += probs[i] * s.cfr(child, traverser, mine, others*probs[i])
+		}
+		return u
+	}
+
+	n := s.node(st.InfoSet(), st.NumActions())
+	strategy := n.current()
+	utils := make([]float64, len(strategy))
+	u := 0.0
+	for a, p := range strategy {
+		if st.Player() == traverser {
+			utils[a] = s.cfr(st.Play(a), traverser, mine*p, others)
+		} else {
+			utils[a] = s.cfr(st.Play(a), traverser, mine, others*p)
+		}
+		u += p * utils[a]
+	}
+	if st.Player() == traverser {
+		if !n.touched {
+			if n.delta == nil {
+				n.delta = make([]float64, len(strategy))
+			}
+			n.touched = true
+			s.touched = append(s.touched, n)
+		}
+		for a, p := range strategy {
+			n.delta[a] += others * (utils[a] - u)
+			n.strategySum[a] += float64(s.iteration) * mine * p
+		}
+	}
+	return u
+}
+
+// MCCFR runs one iteration of external-sampling Monte Carlo CFR from root
+// for each player in turn: chance and the opponent are sampled, every action
+// of the player being updated is explored.
+func (s *Solver) MCCFR(root State, rng *rand.Rand) {
+	s.iteration++
+	for player := 0; player < 2; player++ {
+		s.mccfr(root, player, rng)
+	}
+}
+
+func (s *Solver) mccfr(st State, traverser int, rng *rand.Rand) float64 {
+	switch {
+	case st.Terminal():
+		return st.Utility(traverser)
+	case st.Chance():
+		return s.mccfr(sampleChance(st, rng), traverser, rng)
+	}
+
+	n := s.node(st.InfoSet(), st.NumActions())
+	strategy := n.current()
+	if st.Player() != traverser {
+		for a, p := range strategy {
+			n.strategySum[a] += p
+		}
+		return s.mccfr(st.Play(sample(strategy, rng)), traverser, rng)
+	}
+	utils := make([]float64, len(strategy))
+	u := 0.0
+	for a, p := range strategy {
+		utils[a] = s.mccfr(st.Play(a), traverser, rng)
+		u += p * utils[a]
+	}
+	for a := range strategy {
+		n.regret[a] += utils[a] - u
+	}
+	return u
+}
+
+// sampleChance picks a child of a chance node.
+func sampleChance(st State, rng *rand.Rand) State {
+	if sampler, ok := st.(Sampler); ok {
+		return sampler.Sample(rng)
+	}
+	children, probs := st.Outcomes()
+	return children[sample(probs, rng)]
+}
+
+// sample picks an index with the given probabilities.
+func sample(probs []float64, rng *rand.Rand) int {
+	x := rng.Float64()
+	for i, p := range probs {
+		if x < p {
+			return i
+		}
+		x -= p
+	}
+	return len(probs) - 1
+}
+
+// Value returns player 0's expected utility when both players play
+// strategy. Every chance node must list its outcomes.
+func Value(st State, strategy Strategy) float64 {
+	switch {
+	case st.Terminal():
+		return st.Utility(0)
+	case st.Chance():
+		children, probs := st.Outcomes()
+		u := 0.0
+		for i, child := range children {
+			u += probs[i] * Value(child, strategy)
+		}
+		return u
+	}
+	u := 0.0
+	for a, p := range strategy.Probabilities(st.InfoSet(), st.NumActions()) {
+		if p > 0 {
+			u += p * Value(st.Play(a), strategy)
+		}
+	}
+	return u
+}
+
+// Exploitability returns how much a best response gains against strategy,
+// averaged over the two players; it is zero at an equilibrium. Every chance
+// node must list its outcomes.
+func Exploitability(root State, strategy Strategy) float64 {
+	total := 0.0
+	for player := 0; player < 2; player++ {
+		br := &bestResponse{player: player, strategy: strategy, histories: map[string][]weighted{}, choice: map[string]int{}}
+		br.collect(root, 1)
+		total += br.value(root)
+	}
+	return total / 2
+}
+
+// weighted is a state and the probability that chance and the opponent
+// reach it.
+type weighted struct {
+	state  State
+	weight float64
+}
+
+// bestResponse finds player's best pure strategy against strategy: at each
+// of its information sets, the action with the highest value summed over
+// the states in the set, weighted by how likely each is.
+type bestResponse struct {
+	player    int
+	strategy  Strategy
+	histories map[string][]weighted
+	choice    map[string]int
+}
+
+func (br *bestResponse) collect(st State, weight float64) {
+	switch {
+	case st.Terminal():
+		return
+	case st.Chance():
+		children, probs := st.Outcomes()
+		for i, child := range children {
+			br.collect(child, weight*probs[i])
+		}
+		return
+	}
+	if st.Player() == br.player {
+		key := st.InfoSet()
+		br.histories[key] = append(br.histories[key], weighted{st, weight})
+		for a := 0; a < st.NumActions(); a++ {
+			br.collect(st.Play(a), weight)
+		}
+		return
+	}
+	for a, p := range br.strategy.Probabilities(st.InfoSet(), st.NumActions()) {
+		if p > 0 {
+			br.collect(st.Play(a), weight*p)
+		}
+	}
+}
+
+func (br *bestResponse) value(st State) float64 {
+	switch {
+	case st.Terminal():
+		return st.Utility(br.player)
+	case st.Chance():
+		children, probs := st.Outcomes()
+		u := 0.0
+		for i, child := range children {
+			u += probs[i] * br.value(child)
+		}
+		return u
+	}
+	if st.Player() == br.player {
+		return br.value(st.Play(br.best(st)))
+	}
+	u := 0.0
+	for a, p := range br.strategy.Probabilities(st.InfoSet(), st.NumActions()) {
+		if p > 0 {
+			u += p * br.value(st.Play(a))
+		}
+	}
+	return u
+}
+
+// best returns the best response's action at st's information set.
+func (br *bestResponse) best(st State) int {
+	key := st.InfoSet()
+	if a, ok := br.choice[key]; ok {
+		return a
+	}
+	best, bestValue := 0, 0.0
+	for a := 0; a < st.NumActions(); a++ {
+		v := 0.0
+		for _, h := range br.histories[key] {
+			v += h.weight * br.value(h.state.Play(a))
+		}
+		if a == 0 || v > bestValue {
+			best, bestValue = a, v
+		}
+	}
+	br.choice[key] = best
+	return best
+}
@@ -0,0 +1,256 @@
+package cfr
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// kuhn is Kuhn poker: a three card deck, one card each, an ante of one and
+// a single bet of one. Its value to the first player is -1/18.
+type kuhn struct {
+	dealt   bool
+	cards   [2]int
+	history string
+}
+
+func (k kuhn) Terminal() bool {
+	switch k.history {
+	case "pp", "bb", "bp", "pbb", "pbp":
+		return true
+	}
+	return false
+}
+
+func (k kuhn) Utility(player int) float64 {
+	u := 0.0
+	switch k.history {
+	case "bp":
+		u = 1
+	case "pbp":
+		u = -1
+	default:
+		u = 1
+		if len(k.history) > 2 || k.history == "bb" {
+			u = 2
+		}
+		if k.cards[0] < k.cards[1] {
+			u = -u
+		}
+	}
+	if player == 1 {
+		return -u
+	}
+	return u
+}
+
+func (k kuhn) Chance() bool { return !k.dealt }
+
+func (k kuhn) Outcomes() ([]State, []float64) {
+	var children []State
+	var probs []float64
+	for a := 0; a < 3; a++ {
+		for b := 0; b < 3; b++ {
+			if a != b {
+				children = append(children, kuhn{dealt: true, cards: [2]int{a, b}})
+				probs = append(probs, 1.0/6)
+			}
+		}
+	}
+	return children, probs
+}
+
+func (k kuhn) Player() int     { return len(k.history) % 2 }
+func (k kuhn) InfoSet() string { return strconv.Itoa(k.cards[k.Player()]) + k.history }
+func (k kuhn) NumActions() int { return 2 }
+
+func (k kuhn) Play(action int) State {
+	k.history += "pb"[action : action+1]
+	return k
+}
+
+// leduc is Leduc hold'em: a six card deck of two suits of J, Q and K, one
+// private card each, a public card after the first round, an ante of one,
+// bets of two then four and at most two raises a round.
+type leduc struct {
+	dealt     bool
+	cards     [2]int
+	public    int
+	round     int
+	committed [2]int
+	toAct     int
+	raises    int
+	acted     int
+	folded    int
+	history   string
+}
+
+func newLeduc() leduc {
+	return leduc{public: -1, folded: -1, committed: [2]int{1, 1}}
+}
+
+func (l leduc) Terminal() bool {
+	return l.folded >= 0 || l.round == 2
+}
+
+func (l leduc) Utility(player int) float64 {
+	var u float64
+	switch {
+	case l.folded >= 0:
+		u = float64(l.committed[1])
+		if l.folded == 0 {
+			u = -float64(l.committed[0])
+		}
+	default:
+		u = float64(l.committed[0])
+		switch a, b := l.strength(0), l.strength(1); {
+		case a == b:
+			u = 0
+		case a < b:
+			u = -u
+		}
+	}
+	if player == 1 {
+		return -u
+	}
+	return u
+}
+
+// strength ranks a player's hand: a pair with the public card beats any
+// card alone.
+func (l leduc) strength(player int) int {
+	rank := l.cards[player] / 2
+	if rank == l.public/2 {
+		return 10 + rank
+	}
+	return rank
+}
+
+func (l leduc) Chance() bool {
+	return !l.dealt || (l.round == 1 && l.public < 0)
+}
+
+func (l leduc) Outcomes() ([]State, []float64) {
+	var children []State
+	var probs []float64
+	if !l.dealt {
+		for a := 0; a < 6; a++ {
+			for b := 0; b < 6; b++ {
+				if a != b {
+					child := l
+					child.dealt, child.cards = true, [2]int{a, b}
+					children = append(children, child)
+					probs = append(probs, 1.0/30)
+				}
+			}
+		}
+		return children, probs
+	}
+	for c := 0; c < 6; c++ {
+		if c != l.cards[0] && c != l.cards[1] {
+			child := l
+			child.public = c
+			children = append(children, child)
+			probs = append(probs, 1.0/4)
+		}
+	}
+	return children, probs
+}
+
+func (l leduc) Player() int { return l.toAct }
+
+func (l leduc) InfoSet() string {
+	key := strconv.Itoa(l.cards[l.toAct]/2) + "|" + l.history
+	if l.public >= 0 {
+		key += "|" + strconv.Itoa(l.public/2)
+	}
+	return key
+}
+
+// moves lists the legal actions: fold, call (or check) and raise.
+func (l leduc) moves() string {
+	m := "c"
+	if l.committed[l.toAct] < l.committed[1-l.toAct] {
+		m = "fc"
+	}
+	if l.raises < 2 {
+		m += "r"
+	}
+	return m
+}
+
+func (l leduc) NumActions() int { return len(l.moves()) }
+
+func (l leduc) Play(action int) State {
+	move := l.moves()[action]
+	l.history += string(move)
+	other := 1 - l.toAct
+	switch move {
+	case 'f':
+		l.folded = l.toAct
+		return l
+	case 'c':
+		l.committed[l.toAct] = l.committed[other]
+	case 'r':
+		l.committed[l.toAct] = l.committed[other] + 2*(l.round+1)
+		l.raises++
+	}
+	l.acted++
+	l.toAct = other
+	if l.acted >= 2 && l.committed[0] == l.committed[1] {
+		l.round++
+		l.acted, l.raises, l.toAct = 0, 0, 0
+		l.history += "/"
+	}
+	return l
+}
+
+func TestCFRKuhn(t *testing.T) {
+	s := NewSolver()
+	for i := 0; i < 1000; i++ {
+		s.CFR(kuhn{})
+	}
+	strategy := s.Strategy()
+	if v := Value(kuhn{}, strategy); math.Abs(v+1.0/18) > 1e-3 {
+		t.Errorf("game value = %v, want -1/18", v)
+	}
+	if e := Exploitability(kuhn{}, strategy); e > 1e-3 {
+		t.Errorf("exploitability = %v", e)
+	}
+	// The second player always bets the king after a pass and never calls
+	// a bet with the jack.
+	if p := strategy["2p"]; p[1] < 0.99 {
+		t.Errorf("king after a pass bets with %v, want 1", p[1])
+	}
+	if p := strategy["0b"]; p[1] > 0.01 {
+		t.Errorf("jack facing a bet calls with %v, want 0", p[1])
+	}
+}
+
+func TestMCCFRKuhn(t *testing.T) {
+	s := NewSolver()
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		s.MCCFR(kuhn{}, rng)
+	}
+	if e := Exploitability(kuhn{}, s.Strategy()); e > 0.01 {
+		t.Errorf("exploitability = %v", e)
+	}
+}
+
+func TestCFRLeduc(t *testing.T) {
+	s := NewSolver()
+	root := newLeduc()
+	for i := 0; i < 300; i++ {
+		s.CFR(root)
+	}
+	strategy := s.Strategy()
+	if e := Exploitability(root, strategy); e > 0.01 {
+		t.Errorf("exploitability = %v", e)
+	}
+	// The first player loses about 0.0856 a hand at equilibrium.
+	if v := Value(root, strategy); math.Abs(v+0.0856) > 0.01 {
+		t.Errorf("game value = %v, want about -0.0856", v)
+	}
+}
@@ -0,0 +1,265 @@
+package cfr
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// Abstraction describes the heads-up no-limit hold'em game the solver plays:
+// blinds of 1 and 2, both players starting with Stack, hands told apart
+// only by their strength bucket on each street and at most MaxRaises bets
+// or raises a street, each of half the pot, the pot or all in.
+type Abstraction struct {
+	Buckets   int `json:"buckets"`
+	Stack     int `json:"stack"`
+	MaxRaises int `json:"max_raises"`
+}
+
+// DefaultAbstraction returns 8 buckets a street, 100 big blind stacks and two
+// raises a street.
+func DefaultAbstraction() Abstraction {
+	return Abstraction{Buckets: 8, Stack: 200, MaxRaises: 2}
+}
+
+// Moves of the abstract game, as they appear in information sets.
+const (
+	Fold    = 'f'
+	Call    = 'c' // or check
+	HalfPot = 'h'
+	Pot     = 'p'
+	AllIn   = 'a'
+)
+
+// Bucket returns the strength bucket, 0 the weakest, of hole cards with the
+// board so far: before the flop by PreflopStrength, after it by the share
+// of five-card ranks the best hand beats.
+func (a Abstraction) Bucket(hole, board []poker.Card) int {
+	var strength float64
+	if len(board) < 3 {
+		strength = cards.PreflopStrength(hole)
+	} else {
+		rank := poker.Evaluate(append(append([]poker.Card(nil), hole...), board...))
+		strength = 1 - float64(rank)/7462
+	}
+	b := int(strength * float64(a.Buckets))
+	if b >= a.Buckets {
+		b = a.Buckets - 1
+	}
+	return b
+}
+
+// Root returns the deal that starts every hand of the abstract game.
+func (a Abstraction) Root() State {
+	return holdemDeal{abs: a}
+}
+
+// holdemDeal is the chance node dealing both hands and the whole board.
+type holdemDeal struct {
+	abs Abstraction
+}
+
+func (d holdemDeal) Terminal() bool                 { return false }
+func (d holdemDeal) Utility(player int) float64     { return 0 }
+func (d holdemDeal) Chance() bool                   { return true }
+func (d holdemDeal) Outcomes() ([]State, []float64) { return nil, nil }
+func (d holdemDeal) Player() int                    { return 0 }
+func (d holdemDeal) InfoSet() string                { return "" }
+func (d holdemDeal) NumActions() int                { return 0 }
+func (d holdemDeal) Play(action int) State          { return d }
+
+// Sample implements Sampler.
+func (d holdemDeal) Sample(rng *rand.Rand) State {
+	deck := cards.All()
+	for i := 0; i < 9; i++ {
+		j := i + rng.Intn(len(deck)-i)
+		deck[i], deck[j] = deck[j], deck[i]
+	}
+	hole := [2][]poker.Card{deck[0:2:2], deck[2:4:4]}
+	return newHoldem(d.abs, hole, deck[4:9:9])
+}
+
+// holdem is a state of the abstract game after the deal. Player 0 is the
+// button: it posts the small blind and acts first before the flop, last
+// after it.
+type holdem struct {
+	abs   *Abstraction
+	hole  [2][]poker.Card
+	board []poker.Card
+	// buckets[p][street] is player p's bucket on each street.
+	buckets   [2][4]int
+	street    int
+	committed [2]int
+	// streetBet is what each player has put in on this street.
+	streetBet [2]int
+	toAct     int
+	raises    int
+	acted     int
+	folded    int
+	history   string
+}
+
+// newHoldem starts the betting on a deal. Either hand may be nil when only
+// the other player's information sets are wanted.
+func newHoldem(abs Abstraction, hole [2][]poker.Card, board []poker.Card) holdem {
+	h := holdem{abs: &abs, hole: hole, board: board, folded: -1}
+	for p := range hole {
+		if hole[p] == nil {
+			continue
+		}
+		h.buckets[p][0] = abs.Bucket(hole[p], nil)
+		for street := 1; street < 4 && len(board) >= street+2; street++ {
+			h.buckets[p][street] = abs.Bucket(hole[p], board[:street+2])
+		}
+	}
+	h.committed = [2]int{1, 2}
+	h.streetBet = [2]int{1, 2}
+	return h
+}
+
+func (h holdem) Terminal() bool {
+	return h.folded >= 0 || h.street == 4
+}
+
+func (h holdem) Utility(player int) float64 {
+	other := 1 - player
+	switch {
+	case h.folded == player:
+		return -float64(h.committed[player])
+	case h.folded == other:
+		return float64(h.committed[other])
+	}
+	mine := poker.Evaluate(append(append([]poker.Card(nil), h.hole[player]...), h.board...))
+	theirs := poker.Evaluate(append(append([]poker.Card(nil), h.hole[other]...), h.board...))
+	switch {
+	case mine < theirs:
+		return float64(h.committed[other])
+	case mine > theirs:
+		return -float64(h.committed[player])
+	}
+	return 0
+}
+
+func (h holdem) Chance() bool                   { return false }
+func (h holdem) Outcomes() ([]State, []float64) { return nil, nil }
+func (h holdem) Player() int                    { return h.toAct }
+
+// InfoSet is the player's buckets so far and the moves of the hand, streets
+// separated by '/', e.g. "5.3|cp/h".
+func (h holdem) InfoSet() string {
+	var b strings.Builder
+	for street := 0; street <= h.street; street++ {
+		if street > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.Itoa(h.buckets[h.toAct][street]))
+	}
+	b.WriteByte('|')
+	b.WriteString(h.history)
+	return b.String()
+}
+
+// moves lists the legal moves. Bet sizes that come to all in are dropped in
+// favour of AllIn.
+func (h holdem) moves() []byte {
+	other := 1 - h.toAct
+	toCall := h.streetBet[other] - h.streetBet[h.toAct]
+	var m []byte
+	if toCall > 0 {
+		m = append(m, Fold)
+	}
+	m = append(m, Call)
+	if h.raises < h.abs.MaxRaises && h.committed[other] < h.abs.Stack {
+		for _, move := range []byte{HalfPot, Pot} {
+			if h.raiseTo(move) < h.abs.Stack-h.committed[h.toAct]+h.streetBet[h.toAct] {
+				m = append(m, move)
+			}
+		}
+		m = append(m, AllIn)
+	}
+	return m
+}
+
+// raiseTo returns the street bet a raise comes to.
+func (h holdem) raiseTo(move byte) int {
+	other := 1 - h.toAct
+	toCall := h.streetBet[other] - h.streetBet[h.toAct]
+	pot := h.committed[0] + h.committed[1] + toCall
+	by := 0
+	switch move {
+	case HalfPot:
+		by = int(math.Round(float64(pot) / 2))
+	case Pot:
+		by = pot
+	default:
+		return h.abs.Stack - h.committed[h.toAct] + h.streetBet[h.toAct]
+	}
+	if by < 2 {
+		by = 2
+	}
+	return h.streetBet[other] + by
+}
+
+func (h holdem) NumActions() int { return len(h.moves()) }
+
+func (h holdem) Play(action int) State {
+	return h.play(h.moves()[action])
+}
+
+// play makes a move, which must be legal.
+func (h holdem) play(move byte) holdem {
+	other := 1 - h.toAct
+	h.history += string(move)
+	switch move {
+	case Fold:
+		h.folded = h.toAct
+		return h
+	case Call:
+		h.committed[h.toAct] += h.streetBet[other] - h.streetBet[h.toAct]
+		h.streetBet[h.toAct] = h.streetBet[other]
+	default:
+		to := h.raiseTo(move)
+		h.committed[h.toAct] += to - h.streetBet[h.toAct]
+		h.streetBet[h.toAct] = to
+		h.raises++
+	}
+	h.acted++
+	h.toAct = other
+	if h.acted >= 2 && h.streetBet[0] == h.streetBet[1] {
+		h.street++
+		if h.committed[0] == h.abs.Stack {
+			// All in: the board runs out with no more betting.
+			h.street = 4
+		}
+		h.streetBet = [2]int{}
+		h.acted, h.raises, h.toAct = 0, 0, 1
+		if h.street < 4 {
+			h.history += "/"
+		}
+	}
+	return h
+}
+
+// legal returns the legal move closest to move: a raise that is no longer
+// allowed becomes a call, a fold when checking is free becomes a check.
+func (h holdem) legal(move byte) byte {
+	moves := h.moves()
+	for _, m := range moves {
+		if m == move {
+			return m
+		}
+	}
+	switch move {
+	case HalfPot, Pot:
+		for _, m := range moves {
+			if m == AllIn {
+				return AllIn
+			}
+		}
+	}
+	return Call
+}
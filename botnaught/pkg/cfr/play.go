@@ -0,0 +1,183 @@
+package cfr
+
+import (
+	"math"
+	"math/rand"
+
+	poker "github.com/chehsunliu/poker"
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	handlog "go-poker-project/Botnaught/botnaught/pkg/handlog"
+)
+
+// Act picks an action for the player holding cards in g by mapping the hand
+// so far onto the abstraction and sampling the table's strategy there. Live
+// raises map to the nearest abstract size by fraction of the pot. ok is
+// false, and the caller should decide some other way, when the game isn't
+// heads-up, its stacks aren't as deep in big blinds as the abstraction's,
+// its hand log can't be followed, or the information set was never reached
+// in training.
+func (t *Table) Act(g game.Game, rng *rand.Rand) (action game.Action, move byte, ok bool) {
+	if len(g.PokerPlayers) != 2 {
+		return action, 0, false
+	}
+	// The abstraction plays blinds of 1 and 2: Stack/2 big blinds deep
+	if g.BigBlind <= 0 || g.StartingStack*2 != t.Abstraction.Stack*g.BigBlind {
+		return action, 0, false
+	}
+	var me game.PokerPlayer
+	for _, p := range g.PokerPlayers {
+		if len(p.HoleCards) > 0 {
+			me = p
+		}
+	}
+	if len(me.HoleCards) != 2 {
+		return action, 0, false
+	}
+
+	h, ok := t.replay(g, me)
+	if !ok {
+		return action, 0, false
+	}
+	probs, found := t.Strategy[h.InfoSet()]
+	moves := h.moves()
+	if !found || len(probs) != len(moves) {
+		return action, 0, false
+	}
+	move = moves[sample(probs, rng)]
+	return liveAction(g, me, move), move, true
+}
+
+// replay plays the current hand of g's hand log through the abstract game
+// and returns the state me is to act in.
+func (t *Table) replay(g game.Game, me game.PokerPlayer) (holdem, bool) {
+	lines := handlog.Current(g.HandLog)
+	button := ""
+	for _, l := range lines {
+		if l.Kind == handlog.BetLine && l.Verb == handlog.SmallBlind {
+			button = l.Player
+		}
+	}
+	if button == "" {
+		return holdem{}, false
+	}
+	position := func(name string) int {
+		if name == button {
+			return 0
+		}
+		return 1
+	}
+	hero := position(me.Name)
+
+	var hole [2][]poker.Card
+	hole[hero] = me.HoleCards
+	h := newHoldem(t.Abstraction, hole, g.CommunityCards)
+
+	// The live hand: chips in this street and in all, by position.
+	var streetBet, committed [2]int
+	for _, l := range lines {
+		switch l.Kind {
+		case handlog.StreetLine:
+			streetBet = [2]int{}
+			continue
+		case handlog.BetLine:
+		default:
+			continue
+		}
+		p := position(l.Player)
+		if l.Verb == handlog.SmallBlind || l.Verb == handlog.BigBlind {
+			committed[p] += l.Amount - streetBet[p]
+			streetBet[p] = l.Amount
+			continue
+		}
+		if h.Terminal() || h.toAct != p {
+			return holdem{}, false
+		}
+
+		move := byte(Call)
+		switch l.Verb {
+		case handlog.Fold:
+			move = Fold
+		case handlog.Raise:
+			move = classifyRaise(streetBet, committed, p, l.Amount, g.StartingStack)
+		}
+		h = h.play(h.legal(move))
+		if l.Verb == handlog.Call || l.Verb == handlog.Raise {
+			committed[p] += l.Amount - streetBet[p]
+			streetBet[p] = l.Amount
+		}
+	}
+
+	street := 0
+	if n := len(g.CommunityCards); n >= 3 {
+		street = n - 2
+	}
+	if h.Terminal() || h.toAct != hero || h.street != street {
+		return holdem{}, false
+	}
+	return h, true
+}
+
+// classifyRaise names the abstract move nearest to a live raise to the
+// given street bet: all in when it leaves no chips behind, otherwise half
+// the pot or the pot, whichever is closer, or all in for overbets.
+func classifyRaise(streetBet, committed [2]int, p, to, stack int) byte {
+	other := 1 - p
+	if stack > 0 && committed[p]+to-streetBet[p] >= stack {
+		return AllIn
+	}
+	toCall := streetBet[other] - streetBet[p]
+	pot := committed[0] + committed[1] + toCall
+	if pot <= 0 {
+		return Pot
+	}
+	switch frac := float64(to-streetBet[other]) / float64(pot); {
+	case frac < 0.75:
+		return HalfPot
+	case frac < 2.5:
+		return Pot
+	}
+	return AllIn
+}
+
+// liveAction turns an abstract move into an action in g.
+func liveAction(g game.Game, me game.PokerPlayer, move byte) game.Action {
+	callOrCheck, canRaise := "check", false
+	for _, a := range g.AvailableActions {
+		switch a {
+		case "call":
+			callOrCheck = a
+		case "raise":
+			canRaise = true
+		}
+	}
+	toCall := g.CurrentBet - me.ChipsCommittedThisAction
+	switch move {
+	case Fold:
+		if toCall <= 0 {
+			return game.Action{SelectedAction: callOrCheck}
+		}
+		return game.Action{SelectedAction: "fold"}
+	case Call:
+		return game.Action{SelectedAction: callOrCheck}
+	}
+	if !canRaise {
+		return game.Action{SelectedAction: callOrCheck}
+	}
+
+	allIn := me.Chips + me.ChipsCommittedThisAction
+	pot := g.PotSize + toCall
+	to := allIn
+	switch move {
+	case HalfPot:
+		to = g.CurrentBet + int(math.Round(float64(pot)/2))
+	case Pot:
+		to = g.CurrentBet + pot
+	}
+	if min := g.CurrentBet + g.BigBlind; to < min {
+		to = min
+	}
+	if to > allIn {
+		to = allIn
+	}
+	return game.Action{SelectedAction: "raise", Value: to}
+}
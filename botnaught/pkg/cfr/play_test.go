@@ -0,0 +1,99 @@
+package cfr_test
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	cfr "go-poker-project/Botnaught/botnaught/pkg/cfr"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+	sim "go-poker-project/Botnaught/botnaught/pkg/sim"
+)
+
+// tablePlayer plays from a table and counts the decisions it couldn't make.
+type tablePlayer struct {
+	table   *cfr.Table
+	rng     *rand.Rand
+	asked   int
+	missed  int
+	actions map[byte]int
+	// played is the last game the table played.
+	played game.Game
+}
+
+func (p *tablePlayer) Health(ctx context.Context) (service.HealthStatus, error) {
+	return service.HealthStatus{Live: true, Ready: true}, nil
+}
+
+func (p *tablePlayer) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	p.asked++
+	a, move, ok := p.table.Act(g, p.rng)
+	if !ok {
+		p.missed++
+		return game.Action{SelectedAction: "fold"}, nil
+	}
+	p.actions[move]++
+	p.played = g
+	return a, nil
+}
+
+func TestTable(t *testing.T) {
+	abs := cfr.Abstraction{Buckets: 3, Stack: 40, MaxRaises: 1}
+	table := cfr.Train(context.Background(), abs, 5000, 1, nil)
+	if table.Iterations != 5000 || len(table.Strategy) == 0 {
+		t.Fatalf("trained %d iterations into %d information sets", table.Iterations, len(table.Strategy))
+	}
+
+	var buf bytes.Buffer
+	if err := table.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := cfr.ReadTable(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, table) {
+		t.Error("table read back differs from the one written")
+	}
+
+	// Heads-up hands at the abstraction's own blinds and stacks should be
+	// followed by the table at almost every decision.
+	players := []*tablePlayer{
+		{table: table, rng: rand.New(rand.NewSource(1)), actions: map[byte]int{}},
+		{table: table, rng: rand.New(rand.NewSource(2)), actions: map[byte]int{}},
+	}
+	for hand := 0; hand < 200; hand++ {
+		_, err := sim.Play(context.Background(), sim.Hand{
+			Number:        hand + 1,
+			Seats:         []sim.Seat{{Name: "A", Bot: players[0]}, {Name: "B", Bot: players[1]}},
+			Stacks:        []int{abs.Stack, abs.Stack},
+			Button:        hand % 2,
+			SmallBlind:    1,
+			BigBlind:      2,
+			StartingStack: abs.Stack,
+			Seed:          int64(hand),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, p := range players {
+		if p.asked == 0 || p.missed*50 > p.asked {
+			t.Errorf("player %d: table missed %d of %d decisions", i, p.missed, p.asked)
+		}
+		t.Logf("player %d: %d decisions, %d missed, moves %v", i, p.asked, p.missed, p.actions)
+	}
+
+	// The same spot with deeper stacks is not the game the table was trained on
+	deep := players[0].played
+	if _, _, ok := table.Act(deep, rand.New(rand.NewSource(1))); !ok {
+		t.Fatal("table no longer plays a spot it played")
+	}
+	deep.StartingStack *= 5
+	if _, _, ok := table.Act(deep, rand.New(rand.NewSource(1))); ok {
+		t.Errorf("table played %d big blinds deep, trained at %d", deep.StartingStack/deep.BigBlind, abs.Stack/2)
+	}
+}
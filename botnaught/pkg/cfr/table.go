@@ -0,0 +1,71 @@
+package cfr
+
+import (
+	"context"
+	"encoding/gob"
+	"io"
+	"math/rand"
+	"os"
+)
+
+// Table is a strategy computed for an abstraction, as saved to disk.
+type Table struct {
+	Abstraction Abstraction
+	Iterations  int
+	Strategy    Strategy
+}
+
+// Train runs MCCFR on the abstraction for the given number of iterations
+// and returns the average strategy. progress, when not nil, is called every
+// 1,000 iterations with the iterations done and the information sets seen.
+// Training stops early, returning what it has, when ctx ends.
+func Train(ctx context.Context, abs Abstraction, iterations int, seed int64, progress func(done, infoSets int)) *Table {
+	s := NewSolver()
+	rng := rand.New(rand.NewSource(seed))
+	root := abs.Root()
+	done := 0
+	for ; done < iterations && ctx.Err() == nil; done++ {
+		s.MCCFR(root, rng)
+		if progress != nil && (done+1)%1000 == 0 {
+			progress(done+1, s.InfoSets())
+		}
+	}
+	return &Table{Abstraction: abs, Iterations: done, Strategy: s.Strategy()}
+}
+
+// Write encodes t with encoding/gob.
+func (t *Table) Write(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(t)
+}
+
+// ReadTable decodes a table written by Write.
+func ReadTable(r io.Reader) (*Table, error) {
+	t := &Table{}
+	if err := gob.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Save writes t to the file at path.
+func (t *Table) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadTable reads the table saved at path.
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTable(f)
+}
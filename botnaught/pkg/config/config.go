@@ -0,0 +1,366 @@
+// Package config loads BotNaught's settings. Every setting has a default and
+// can be overridden, in increasing order of precedence, by a YAML or JSON
+// file, a BOTNAUGHT_* environment variable and a command line flag.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// EnvPrefix starts the name of every environment variable read by Load. The
+// rest of the name is the flag name upper-cased, with '.' and '-' replaced by
+// '_': -http-addr is BOTNAUGHT_HTTP_ADDR.
+const EnvPrefix = "BOTNAUGHT_"
+
+// Config is the complete configuration of a BotNaught process.
+type Config struct {
+	Transport    Transport         `json:"transport" yaml:"transport"`
+	Registration Registration      `json:"registration" yaml:"registration"`
+	Admin        Admin             `json:"admin" yaml:"admin"`
+	Strategy     service.BetParams `json:"strategy" yaml:"strategy"`
+	// StrategyFile, when set, is watched for Bet parameters that override
+	// Strategy while the bots are running.
+	StrategyFile string `json:"strategy_file,omitempty" yaml:"strategy_file,omitempty"`
+	// CFRTable, when set, is a strategy table written by the cfr command
+	// that bots able to play from one use wherever it covers the game.
+	CFRTable string `json:"cfr_table,omitempty" yaml:"cfr_table,omitempty"`
+	// DecisionCache limits the cache of decisions each bot keeps.
+	DecisionCache DecisionCache `json:"decision_cache" yaml:"decision_cache"`
+	// BotStrategy is the strategy of the bot hosted when Bots is empty,
+	// written strategy[,param=value...].
+	BotStrategy string                  `json:"bot_strategy" yaml:"bot_strategy"`
+	Bots        []service.BotDefinition `json:"bots,omitempty" yaml:"bots,omitempty"`
+}
+
+// DecisionCache configures the decision caches; a size of zero turns them
+// off.
+type DecisionCache struct {
+	Size int      `json:"size" yaml:"size"`
+	TTL  Duration `json:"ttl" yaml:"ttl"`
+}
+
+// Transport configures the listeners and tracing.
+type Transport struct {
+	DebugAddr      string `json:"debug_addr" yaml:"debug_addr"`
+	HTTPAddr       string `json:"http_addr" yaml:"http_addr"`
+	GRPCAddr       string `json:"grpc_addr" yaml:"grpc_addr"`
+	ThriftAddr     string `json:"thrift_addr" yaml:"thrift_addr"`
+	ThriftProtocol string `json:"thrift_protocol" yaml:"thrift_protocol"`
+	ThriftBuffer   int    `json:"thrift_buffer" yaml:"thrift_buffer"`
+	ThriftFramed   bool   `json:"thrift_framed" yaml:"thrift_framed"`
+	ZipkinURL      string `json:"zipkin_url" yaml:"zipkin_url"`
+	LightstepToken string `json:"lightstep_token" yaml:"lightstep_token"`
+	AppdashAddr    string `json:"appdash_addr" yaml:"appdash_addr"`
+}
+
+// Registration configures how bots announce themselves to the game server.
+type Registration struct {
+	ServerURL    string   `json:"server_url" yaml:"server_url"`
+	CallbackHost string   `json:"callback_host" yaml:"callback_host"`
+	BotName      string   `json:"bot_name" yaml:"bot_name"`
+	Delay        Duration `json:"delay" yaml:"delay"`
+	Timeout      Duration `json:"timeout" yaml:"timeout"`
+	Retry        Duration `json:"retry" yaml:"retry"`
+}
+
+// Admin configures the admin API on the debug listener.
+type Admin struct {
+	// Token must be presented as a bearer token; the admin API is off
+	// without one.
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() Config {
+	return Config{
+		Transport: Transport{
+			DebugAddr:      ":7080",
+			HTTPAddr:       ":7081",
+			GRPCAddr:       ":8082",
+			ThriftAddr:     ":8083",
+			ThriftProtocol: "binary",
+		},
+		Registration: Registration{
+			ServerURL:    "http://localhost:8888",
+			CallbackHost: "http://localhost",
+			BotName:      "BotNaught",
+			Delay:        Duration{2 * time.Second},
+			Timeout:      Duration{time.Second},
+			Retry:        Duration{5 * time.Second},
+		},
+		Strategy:      service.DefaultBetParams(),
+		BotStrategy:   "basic",
+		DecisionCache: DecisionCache{Size: 1024, TTL: Duration{30 * time.Second}},
+	}
+}
+
+// RegisterFlags binds a flag on fs to every setting except Bots, which can
+// only be given in a file.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	t := &c.Transport
+	fs.StringVar(&t.DebugAddr, "debug.addr", t.DebugAddr, "Debug and metrics listen address")
+	fs.StringVar(&t.HTTPAddr, "http-addr", t.HTTPAddr, "HTTP listen address")
+	fs.StringVar(&t.GRPCAddr, "grpc-addr", t.GRPCAddr, "gRPC listen address")
+	fs.StringVar(&t.ThriftAddr, "thrift-addr", t.ThriftAddr, "Thrift listen address")
+	fs.StringVar(&t.ThriftProtocol, "thrift-protocol", t.ThriftProtocol, "binary, compact, json, simplejson")
+	fs.IntVar(&t.ThriftBuffer, "thrift-buffer", t.ThriftBuffer, "0 for unbuffered")
+	fs.BoolVar(&t.ThriftFramed, "thrift-framed", t.ThriftFramed, "true to enable framing")
+	fs.StringVar(&t.ZipkinURL, "zipkin-url", t.ZipkinURL, "Enable Zipkin tracing via a collector URL e.g. http://localhost:9411/api/v1/spans")
+	fs.StringVar(&t.LightstepToken, "lightstep-token", t.LightstepToken, "Enable LightStep tracing via a LightStep access token")
+	fs.StringVar(&t.AppdashAddr, "appdash-addr", t.AppdashAddr, "Enable Appdash tracing via an Appdash server host:port")
+
+	r := &c.Registration
+	fs.StringVar(&r.ServerURL, "registration.server-url", r.ServerURL, "Game server to register with")
+	fs.StringVar(&r.CallbackHost, "registration.callback-host", r.CallbackHost, "Scheme and host the game server reaches us on")
+	fs.StringVar(&r.BotName, "botname", r.BotName, "The name of the poker bot that will be registered")
+	fs.Var(&r.Delay, "registration.delay", "Wait this long after starting before registering")
+	fs.Var(&r.Timeout, "registration.timeout", "Timeout of each registration attempt")
+	fs.Var(&r.Retry, "registration.retry", "Wait this long between failed registration attempts")
+
+	fs.StringVar(&c.Admin.Token, "admin.token", c.Admin.Token, "Bearer token for the admin API on the debug listener; empty disables it")
+
+	for _, spec := range service.BetParamSpecs {
+		field := spec.Field(&c.Strategy)
+		fs.Float64Var(field, "strategy."+strings.Replace(spec.Name, "_", "-", -1), *field, spec.Usage)
+	}
+	fs.StringVar(&c.StrategyFile, "strategy-file", c.StrategyFile, "YAML or JSON file of Bet parameters, reloaded when it changes")
+	fs.IntVar(&c.DecisionCache.Size, "decision-cache.size", c.DecisionCache.Size, "Decisions each bot caches; 0 turns the cache off")
+	fs.Var(&c.DecisionCache.TTL, "decision-cache.ttl", "How long a cached decision is reused")
+	fs.StringVar(&c.CFRTable, "cfr-table", c.CFRTable, "Strategy table from the cfr command to play heads-up hands from")
+	fs.StringVar(&c.BotStrategy, "bot-strategy", c.BotStrategy, "Strategy of the bot when none are configured, as strategy[,param=value...]: basic, call, raise, random, tight-passive, loose-aggressive or equity")
+}
+
+// EnvName returns the environment variable that overrides the named flag.
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flagName))
+}
+
+// Load fills c from its defaults, then the file at path (or at
+// $BOTNAUGHT_CONFIG when path is empty), then the environment, then the flags
+// explicitly set on fs, which must already be parsed with c's flags
+// registered. The result is validated.
+func (c *Config) Load(fs *flag.FlagSet, path string) error {
+	explicit := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = f.Value.String()
+	})
+
+	*c = Default()
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if path != "" {
+		if err := c.readFile(path); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if value, ok := os.LookupEnv(EnvName(f.Name)); ok && err == nil {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("%s: %v", EnvName(f.Name), setErr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for name, value := range explicit {
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("-%s: %v", name, err)
+		}
+	}
+	return c.Validate()
+}
+
+// readFile overlays the settings in a YAML or JSON file onto c.
+func (c *Config) readFile(path string) error {
+	return unmarshalFile(path, c, false)
+}
+
+// ReadParams returns base overlaid with the Bet parameters in a YAML or JSON
+// file, which holds the same keys as the strategy section of a config file
+// and no others: a whole config file, such as tune writes, is rejected
+// rather than silently leaving base as it is.
+func ReadParams(path string, base service.BetParams) (service.BetParams, error) {
+	if err := unmarshalFile(path, &base, true); err != nil {
+		return base, err
+	}
+	if err := base.Validate(); err != nil {
+		return base, fmt.Errorf("%s: %v", path, err)
+	}
+	return base, nil
+}
+
+// unmarshalFile decodes a YAML or JSON file, chosen by extension, into v.
+// When strict, keys v has no field for are an error.
+func unmarshalFile(path string, v interface{}, strict bool) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if strict {
+			err = yaml.UnmarshalStrict(data, v)
+		} else {
+			err = yaml.Unmarshal(data, v)
+		}
+	case ".json":
+		d := json.NewDecoder(bytes.NewReader(data))
+		if strict {
+			d.DisallowUnknownFields()
+		}
+		err = d.Decode(v)
+	default:
+		return fmt.Errorf("%s: unknown format, want .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// Validate reports the first setting that would stop the process working.
+func (c *Config) Validate() error {
+	t := c.Transport
+	for name, addr := range map[string]string{"debug_addr": t.DebugAddr, "http_addr": t.HTTPAddr} {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("transport.%s %q: %v", name, addr, err)
+		}
+	}
+	switch t.ThriftProtocol {
+	case "binary", "compact", "json", "simplejson":
+	default:
+		return fmt.Errorf("transport.thrift_protocol %q: want binary, compact, json or simplejson", t.ThriftProtocol)
+	}
+
+	r := c.Registration
+	for name, raw := range map[string]string{"server_url": r.ServerURL, "callback_host": r.CallbackHost} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("registration.%s %q: %v", name, raw, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("registration.%s %q: want an http or https URL", name, raw)
+		}
+	}
+	if r.BotName == "" {
+		return fmt.Errorf("registration.bot_name is empty")
+	}
+	if r.Timeout.Duration <= 0 || r.Retry.Duration <= 0 || r.Delay.Duration < 0 {
+		return fmt.Errorf("registration delay, timeout and retry must be positive")
+	}
+
+	if c.DecisionCache.Size < 0 || (c.DecisionCache.Size > 0 && c.DecisionCache.TTL.Duration <= 0) {
+		return fmt.Errorf("decision_cache: size must not be negative and ttl must be positive")
+	}
+
+	if err := c.Strategy.Validate(); err != nil {
+		return fmt.Errorf("strategy: %v", err)
+	}
+	defs, err := c.BotDefinitions()
+	if err != nil {
+		return err
+	}
+	return service.Validate(defs)
+}
+
+// BotDefinitions returns the bots to host: those listed in the configuration,
+// or a single bot named Registration.BotName playing BotStrategy.
+func (c *Config) BotDefinitions() ([]service.BotDefinition, error) {
+	if len(c.Bots) > 0 {
+		return c.Bots, nil
+	}
+	def, err := service.ParseBotDefinition(c.Registration.BotName + "=" + c.BotStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("bot_strategy: %v", err)
+	}
+	return []service.BotDefinition{def}, nil
+}
+
+// Redacted stands in for the tokens Print leaves out.
+const Redacted = "REDACTED"
+
+// Print writes c as YAML, with the tokens that are set written as Redacted.
+func (c *Config) Print(w io.Writer) error {
+	printed := *c
+	for _, token := range []*string{&printed.Admin.Token, &printed.Transport.LightstepToken} {
+		if *token != "" {
+			*token = Redacted
+		}
+	}
+	data, err := yaml.Marshal(printed)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// PrintStrategy writes p as a configuration file that holds only its
+// strategy section, leaving out the tokens and addresses of the rest.
+func PrintStrategy(w io.Writer, p service.BetParams) error {
+	data, err := yaml.Marshal(struct {
+		Strategy service.BetParams `json:"strategy" yaml:"strategy"`
+	}{p})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Duration is a time.Duration written as "2s" in files and flags.
+type Duration struct {
+	time.Duration
+}
+
+// Set implements flag.Value.
+func (d *Duration) Set(s string) (err error) {
+	d.Duration, err = time.ParseDuration(s)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.Set(s)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return d.Set(s)
+}
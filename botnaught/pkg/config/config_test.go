@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botnaught-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "botnaught.json")
+	file := `{
+		"transport": {"http_addr": ":9001", "debug_addr": ":9002"},
+		"registration": {"bot_name": "FromFile", "timeout": "3s"},
+		"strategy": {"preflop_raise": 0.3, "preflop_call": 0.5}
+	}`
+	if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("BOTNAUGHT_DEBUG_ADDR", ":9003")
+	os.Setenv("BOTNAUGHT_STRATEGY_PREFLOP_CALL", "0.4")
+	os.Setenv("BOTNAUGHT_BOTNAME", "FromEnv")
+	defer os.Unsetenv("BOTNAUGHT_DEBUG_ADDR")
+	defer os.Unsetenv("BOTNAUGHT_STRATEGY_PREFLOP_CALL")
+	defer os.Unsetenv("BOTNAUGHT_BOTNAME")
+
+	cfg := Default()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-botname", "FromFlag"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Load(fs, path); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"file over default", cfg.Transport.HTTPAddr, ":9001"},
+		{"env over file", cfg.Transport.DebugAddr, ":9003"},
+		{"flag over env", cfg.Registration.BotName, "FromFlag"},
+		{"file duration", cfg.Registration.Timeout.String(), "3s"},
+		{"file strategy", cfg.Strategy.PreflopRaise, 0.3},
+		{"env strategy", cfg.Strategy.PreflopCall, 0.4},
+		{"default", cfg.Transport.ThriftProtocol, "binary"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestPrintStrategy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botnaught-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := Default()
+	cfg.Admin.Token, cfg.Transport.LightstepToken = "admin-secret", "lightstep-secret"
+	cfg.Strategy.AllInStrength = 0.9
+	var buf bytes.Buffer
+	if err := PrintStrategy(&buf, cfg.Strategy); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("PrintStrategy wrote a secret:\n%s", buf.String())
+	}
+	var all bytes.Buffer
+	if err := cfg.Print(&all); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(all.String(), "secret") || !strings.Contains(all.String(), Redacted) || cfg.Admin.Token != "admin-secret" {
+		t.Errorf("Print wrote a secret or changed the config:\n%s", all.String())
+	}
+
+	path := filepath.Join(dir, "tuned.yaml")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded := Default()
+	if err := loaded.Load(flag.NewFlagSet("test", flag.ContinueOnError), path); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Strategy != cfg.Strategy {
+		t.Errorf("loaded strategy %+v, want %+v", loaded.Strategy, cfg.Strategy)
+	}
+	// As a strategy file it holds a key no parameter has
+	if _, err := ReadParams(path, Default().Strategy); err == nil {
+		t.Error("ReadParams accepted a file with a strategy section")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"-http-addr", "7081"},
+		{"-registration.server-url", "localhost:8888"},
+		{"-strategy.preflop-raise", "2"},
+		{"-botname", ""},
+	} {
+		cfg := Default()
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		cfg.RegisterFlags(fs)
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.Load(fs, ""); err == nil {
+			t.Errorf("Load(%v) accepted an invalid configuration", args)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Package draws finds the draws hole cards have on the flop and turn and
+// counts their outs. Raw outs are every unseen card that completes a
+// flush or straight for us, or pairs an overcard; clean outs discount those
+// that may also complete a better hand for an opponent, so that equity from
+// the rule of 2 and 4 isn't overstated.
+package draws
+
+import (
+	"fmt"
+	"strings"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// Analysis describes the draws of hole cards on a board.
+type Analysis struct {
+	// FlushDraw is four cards to a flush, at least one of them ours.
+	FlushDraw bool
+	// OpenEnded is a straight draw with two or more ranks to hit, a double
+	// gutshot included; Gutshot one with a single rank.
+	OpenEnded bool
+	Gutshot   bool
+	// BackdoorFlush and BackdoorStraight are three to a flush and three to
+	// a straight on the flop, needing both the turn and the river.
+	BackdoorFlush    bool
+	BackdoorStraight bool
+	// Overcards is how many of our unpaired hole cards are above every
+	// board card, when we have no pair, flush or straight.
+	Overcards int
+	// Outs are the unseen cards that improve us to a flush or straight or
+	// pair an overcard.
+	Outs []poker.Card
+	// Clean is the number of outs, each counted down to a half when it may
+	// also give an opponent a better hand.
+	Clean float64
+}
+
+// Analyze finds the draws of hole on the flop or turn. Before the flop and
+// on the river there are none.
+func Analyze(hole, board []poker.Card) Analysis {
+	var a Analysis
+	if len(hole) != 2 || len(board) < 3 || len(board) > 4 {
+		return a
+	}
+	ours := append(append([]poker.Card(nil), hole...), board...)
+	made := flushSuit(ours) >= 0 || straight(cards.RankMask(ours))
+
+	// Suits and ranks of our cards and the board.
+	var suitCount, holeSuit [4]int
+	for _, c := range ours {
+		suitCount[cards.Suit(c)]++
+	}
+	for _, c := range hole {
+		holeSuit[cards.Suit(c)]++
+	}
+	boardHigh, boardRanks := -1, map[int]bool{}
+	for _, c := range board {
+		boardRanks[cards.Rank(c)] = true
+		if cards.Rank(c) > boardHigh {
+			boardHigh = cards.Rank(c)
+		}
+	}
+	paired := cards.Rank(hole[0]) == cards.Rank(hole[1]) ||
+		boardRanks[cards.Rank(hole[0])] || boardRanks[cards.Rank(hole[1])]
+	overRanks := map[int]bool{}
+	if !paired && !made {
+		for _, c := range hole {
+			if cards.Rank(c) > boardHigh {
+				a.Overcards++
+				overRanks[cards.Rank(c)] = true
+			}
+		}
+	}
+
+	straightRanks := map[int]bool{}
+	for _, c := range cards.Without(ours) {
+		next := append(append([]poker.Card(nil), ours...), c)
+		nextBoard := append(append([]poker.Card(nil), board...), c)
+		fs := flushSuit(next)
+		flush := !made && fs >= 0 && holeSuit[fs] > 0
+		str := !made && straight(cards.RankMask(next)) && !straight(cards.RankMask(nextBoard))
+		over := overRanks[cards.Rank(c)]
+		if !flush && !str && !over {
+			continue
+		}
+		a.Outs = append(a.Outs, c)
+		if flush {
+			a.FlushDraw = true
+		}
+		if str {
+			straightRanks[cards.Rank(c)] = true
+		}
+
+		weight := 1.0
+		switch {
+		case flush:
+			s := cards.Suit(c)
+			// A flush made with one hole card is beaten by a bigger one, and
+			// one that pairs the board by a full house.
+			if (holeSuit[s] == 1 && !nutSuited(hole, s)) || boardRanks[cards.Rank(c)] {
+				weight = 0.5
+			}
+		case str:
+			// A straight card that puts three of a suit on the board lets a
+			// flush in.
+			if boardSuited(nextBoard) >= 3 {
+				weight = 0.5
+			}
+		default:
+			// Pairing an overcard is often not best.
+			weight = 0.5
+		}
+		a.Clean += weight
+	}
+	a.OpenEnded = len(straightRanks) >= 2
+	a.Gutshot = len(straightRanks) == 1
+
+	if len(board) == 3 && !made {
+		for s, n := range suitCount {
+			if n == 3 && holeSuit[s] > 0 {
+				a.BackdoorFlush = true
+			}
+		}
+		if !a.OpenEnded && !a.Gutshot {
+			a.BackdoorStraight = backdoorStraight(hole, ours)
+		}
+	}
+	return a
+}
+
+// Equity estimates how often the draws get there by the river, by the rule
+// of 4 on the flop, less a point for every clean out above eight, and 2 on
+// the turn, plus a little for backdoor draws.
+func (a Analysis) Equity(board []poker.Card) float64 {
+	var e float64
+	switch len(board) {
+	case 3:
+		e = 4 * a.Clean
+		if a.Clean > 8 {
+			e -= a.Clean - 8
+		}
+		if a.BackdoorFlush {
+			e += 4
+		}
+		if a.BackdoorStraight {
+			e += 3
+		}
+	case 4:
+		e = 2 * a.Clean
+	}
+	if e > 100 {
+		e = 100
+	}
+	return e / 100
+}
+
+// String lists the draws for decision logs, e.g. "flush draw, gutshot,
+// 10.5 clean outs of 12".
+func (a Analysis) String() string {
+	var names []string
+	for _, d := range []struct {
+		on   bool
+		name string
+	}{
+		{a.FlushDraw, "flush draw"},
+		{a.OpenEnded, "open-ended"},
+		{a.Gutshot, "gutshot"},
+		{a.BackdoorFlush, "backdoor flush"},
+		{a.BackdoorStraight, "backdoor straight"},
+		{a.Overcards > 0, fmt.Sprintf("%d overcards", a.Overcards)},
+	} {
+		if d.on {
+			names = append(names, d.name)
+		}
+	}
+	if len(names) == 0 {
+		return "no draws"
+	}
+	return fmt.Sprintf("%s, %g clean outs of %d", strings.Join(names, ", "), a.Clean, len(a.Outs))
+}
+
+// flushSuit returns the suit with five or more cards, or -1.
+func flushSuit(cs []poker.Card) int {
+	var n [4]int
+	for _, c := range cs {
+		n[cards.Suit(c)]++
+		if n[cards.Suit(c)] >= 5 {
+			return cards.Suit(c)
+		}
+	}
+	return -1
+}
+
+// boardSuited returns the most cards of one suit.
+func boardSuited(cs []poker.Card) int {
+	var n [4]int
+	most := 0
+	for _, c := range cs {
+		n[cards.Suit(c)]++
+		if n[cards.Suit(c)] > most {
+			most = n[cards.Suit(c)]
+		}
+	}
+	return most
+}
+
+// nutSuited reports whether our card of suit s is an ace or king, so a
+// flush made with it alone is likely best.
+func nutSuited(hole []poker.Card, s int) bool {
+	for _, c := range hole {
+		if cards.Suit(c) == s && cards.Rank(c) >= 11 {
+			return true
+		}
+	}
+	return false
+}
+
+// straight reports five consecutive ranks in a mask.
+func straight(m uint16) bool {
+	for low := uint(0); low <= 9; low++ {
+		if m>>low&0x1f == 0x1f {
+			return true
+		}
+	}
+	return false
+}
+
+// backdoorStraight reports three ranks within a five-rank window, one of
+// them ours.
+func backdoorStraight(hole, ours []poker.Card) bool {
+	all, mine := cards.RankMask(ours), cards.RankMask(hole)
+	for low := uint(0); low <= 9; low++ {
+		window := uint16(0x1f) << low
+		if n := cards.Bits(all & window); n >= 3 && mine&window != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package draws
+
+import (
+	"reflect"
+	"testing"
+
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name        string
+		hole, board string
+		want        Analysis
+		outs        int
+		clean       float64
+	}{
+		{"nut flush draw", "AsKs", "2s7s9d", Analysis{FlushDraw: true, Overcards: 2}, 9 + 6, 8.5 + 3},
+		{"open-ended", "5h4d", "7c6s2h", Analysis{OpenEnded: true}, 8, 8},
+		{"gutshot", "9h8d", "Jc7s2h", Analysis{Gutshot: true}, 4, 4},
+		{"combo draw", "5s4s", "7s6s2h", Analysis{FlushDraw: true, OpenEnded: true}, 15, 14.5},
+		{"backdoors", "9s8s", "7s2hKd", Analysis{BackdoorFlush: true, BackdoorStraight: true}, 0, 0},
+		{"made straight has no draws", "9h8d", "7c6s5h", Analysis{}, 0, 0},
+		{"straight card that brings a flush", "5h4d", "7c6c2h", Analysis{OpenEnded: true}, 8, 7},
+		{"one-card flush draw on the turn", "7s2d", "Js9s4s3h", Analysis{FlushDraw: true}, 9, 4.5},
+		{"river", "AsKs", "2s7s9d3h4c", Analysis{}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(cards.MustParse(tt.hole), cards.MustParse(tt.board))
+			if len(got.Outs) != tt.outs || got.Clean != tt.clean {
+				t.Errorf("%d outs, %v clean; want %d, %v", len(got.Outs), got.Clean, tt.outs, tt.clean)
+			}
+			got.Outs, got.Clean = nil, 0
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEquity(t *testing.T) {
+	flop := cards.MustParse("7s6s2h")
+	combo := Analyze(cards.MustParse("5s4s"), flop)
+	if e := combo.Equity(flop); e < 0.45 || e > 0.55 {
+		t.Errorf("15-out combo draw on the flop: equity %v, want about .5", e)
+	}
+	turn := cards.MustParse("7s6s2h3d")
+	if e := Analyze(cards.MustParse("AsKs"), turn).Equity(turn); e < 0.15 || e > 0.25 {
+		t.Errorf("nut flush draw and an overcard on the turn: equity %v", e)
+	}
+	if e := (Analysis{}).Equity(flop); e != 0 {
+		t.Errorf("no draws: equity %v", e)
+	}
+}
@@ -12,14 +12,18 @@ type HealthRequest struct{}
 
 // HealthResponse collects the response parameters for the Health method.
 type HealthResponse struct {
-	Err error `json:"err"`
+	Status service.HealthStatus `json:"status"`
+	Err    error                `json:"err"`
 }
 
 // MakeHealthEndpoint returns an endpoint that invokes Health on the service.
 func MakeHealthEndpoint(s service.BotnaughtService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		err := s.Health(ctx)
-		return HealthResponse{Err: err}, nil
+		status, err := s.Health(ctx)
+		return HealthResponse{
+			Status: status,
+			Err:    err,
+		}, nil
 	}
 }
 
@@ -64,13 +68,13 @@ type Failure interface {
 }
 
 // Health implements Service. Primarily useful in a client.
-func (e Endpoints) Health(ctx context.Context) (err error) {
+func (e Endpoints) Health(ctx context.Context) (status service.HealthStatus, err error) {
 	request := HealthRequest{}
 	response, err := e.HealthEndpoint(ctx, request)
 	if err != nil {
 		return
 	}
-	return response.(HealthResponse).Err
+	return response.(HealthResponse).Status, response.(HealthResponse).Err
 }
 
 // Action implements Service. Primarily useful in a client.
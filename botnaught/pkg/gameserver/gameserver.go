@@ -0,0 +1,364 @@
+// Package gameserver is a stand-in for the game server bots register with.
+// It accepts registrations on /register the way the real server does, seats
+// every registered bot at one table and plays hands by POSTing
+// ActionRequests to the bots' /action endpoints, so the whole HTTP stack can
+// be exercised on one machine.
+package gameserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	log "github.com/go-kit/kit/log"
+	client "go-poker-project/Botnaught/botnaught/client/http"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+	sim "go-poker-project/Botnaught/botnaught/pkg/sim"
+)
+
+// Config describes how the server plays.
+type Config struct {
+	// Players is how many bots must be registered before play starts.
+	Players int
+	// Hands is the number of hands to play; 0 plays until stopped.
+	Hands      int
+	Seed       int64
+	SmallBlind int
+	BigBlind   int
+	// Stack is every seat's stack at the start of every hand.
+	Stack int
+	// ActionTimeout is how long a bot has to act. A bot that runs out of
+	// time checks if it can and folds otherwise.
+	ActionTimeout time.Duration
+	// Pause is the wait between hands.
+	Pause time.Duration
+	// History is how many hands are kept for /results.
+	History int
+}
+
+// DefaultConfig returns a heads-up game at 1/2 blinds with 100 big blind
+// stacks and a second to act.
+func DefaultConfig() Config {
+	return Config{
+		Players:       2,
+		Seed:          1,
+		SmallBlind:    1,
+		BigBlind:      2,
+		Stack:         200,
+		ActionTimeout: time.Second,
+		History:       100,
+	}
+}
+
+// Server registers bots and plays them against each other.
+type Server struct {
+	cfg    Config
+	logger log.Logger
+	// Record, when set, receives every hand as a line of JSON.
+	Record io.Writer
+	// history is what the bots are shown of the hands before; only the
+	// loop dealing the hands touches it.
+	history sim.History
+
+	mu        sync.Mutex
+	players   []*player
+	hands     []HandResult
+	played    int
+	newPlayer chan struct{}
+}
+
+// player is a registered bot.
+type player struct {
+	name     string
+	address  string
+	bot      service.BotnaughtService
+	hands    int
+	chips    int
+	timeouts int64
+	errors   int64
+}
+
+// New returns a server that plays by cfg.
+func New(cfg Config, logger log.Logger) *Server {
+	return &Server{cfg: cfg, logger: logger, newPlayer: make(chan struct{}, 1)}
+}
+
+// Standing is a registered bot's record.
+type Standing struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Hands    int    `json:"hands"`
+	Chips    int    `json:"chips"`
+	Timeouts int64  `json:"timeouts"`
+	Errors   int64  `json:"errors"`
+}
+
+// HandResult is the record of one hand.
+type HandResult struct {
+	Number  int            `json:"number"`
+	Seed    int64          `json:"seed"`
+	Deltas  map[string]int `json:"deltas"`
+	Log     []string       `json:"log"`
+	Errors  []string       `json:"errors,omitempty"`
+	Elapsed string         `json:"elapsed"`
+}
+
+// Results is the answer to GET /results.
+type Results struct {
+	Played    int          `json:"played"`
+	Standings []Standing   `json:"standings"`
+	Hands     []HandResult `json:"hands"`
+}
+
+// Register seats the bot called name, reached at address, from the next
+// hand. A bot registering again under its name replaces itself.
+func (s *Server) Register(address, name string) error {
+	if name == "" {
+		return errors.New("name is empty")
+	}
+	if u, err := url.Parse(address); err != nil || u.Host == "" {
+		return fmt.Errorf("address %q is not a URL", address)
+	}
+	bot, err := client.New(address, nil)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var p *player
+	for _, q := range s.players {
+		if q.name == name {
+			p = q
+		}
+	}
+	if p == nil {
+		p = &player{name: name}
+		s.players = append(s.players, p)
+	}
+	p.address = address
+	p.bot = timeLimited{next: bot, limit: s.cfg.ActionTimeout, timeouts: &p.timeouts, errors: &p.errors}
+	s.logger.Log("registered", name, "address", address, "players", len(s.players))
+	select {
+	case s.newPlayer <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Results returns the standings and the most recent hands.
+func (s *Server) Results() Results {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := Results{Played: s.played, Hands: append([]HandResult(nil), s.hands...)}
+	for _, p := range s.players {
+		r.Standings = append(r.Standings, Standing{
+			Name:     p.name,
+			Address:  p.address,
+			Hands:    p.hands,
+			Chips:    p.chips,
+			Timeouts: atomic.LoadInt64(&p.timeouts),
+			Errors:   atomic.LoadInt64(&p.errors),
+		})
+	}
+	sort.Slice(r.Standings, func(i, j int) bool { return r.Standings[i].Chips > r.Standings[j].Chips })
+	return r
+}
+
+// Run waits for cfg.Players bots and plays until cfg.Hands hands are done
+// or ctx ends.
+func (s *Server) Run(ctx context.Context) error {
+	for hand := 0; s.cfg.Hands == 0 || hand < s.cfg.Hands; hand++ {
+		seated, seats, err := s.waitForPlayers(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.play(ctx, hand, seated, seats); err != nil {
+			return err
+		}
+		if s.cfg.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.cfg.Pause):
+			}
+		}
+	}
+	return nil
+}
+
+// waitForPlayers returns the registered players, and a seat for each, once
+// there are enough.
+func (s *Server) waitForPlayers(ctx context.Context) ([]*player, []sim.Seat, error) {
+	for {
+		s.mu.Lock()
+		seated := append([]*player(nil), s.players...)
+		seats := make([]sim.Seat, len(seated))
+		for i, p := range seated {
+			seats[i] = sim.Seat{Name: p.name, Bot: p.bot}
+		}
+		s.mu.Unlock()
+		if len(seated) >= s.cfg.Players && len(seated) >= 2 {
+			return seated, seats, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-s.newPlayer:
+		}
+	}
+}
+
+// play deals one hand to the seated players, the button moving every hand.
+func (s *Server) play(ctx context.Context, hand int, seated []*player, seats []sim.Seat) error {
+	stacks := make([]int, len(seats))
+	for i := range stacks {
+		stacks[i] = s.cfg.Stack
+	}
+	start := time.Now()
+	seed := s.cfg.Seed + int64(hand)
+	res, err := sim.Play(ctx, sim.Hand{
+		GameID:        fmt.Sprintf("gameserver-%d", s.cfg.Seed),
+		Number:        hand + 1,
+		Seats:         seats,
+		Stacks:        stacks,
+		Button:        hand % len(seats),
+		SmallBlind:    s.cfg.SmallBlind,
+		BigBlind:      s.cfg.BigBlind,
+		StartingStack: s.cfg.Stack,
+		Seed:          seed,
+		Log:           s.history.Log(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		// The hand was cut short; its result means nothing.
+		return err
+	}
+	s.history.Add(res.Log)
+
+	result := HandResult{
+		Number:  hand + 1,
+		Seed:    seed,
+		Deltas:  map[string]int{},
+		Log:     res.Log,
+		Elapsed: time.Since(start).String(),
+	}
+	for _, err := range res.Errors {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	s.mu.Lock()
+	for i, p := range seated {
+		p.hands++
+		p.chips += res.Deltas[i]
+		result.Deltas[p.name] = res.Deltas[i]
+	}
+	s.played++
+	s.hands = append(s.hands, result)
+	if s.cfg.History >= 0 && len(s.hands) > s.cfg.History {
+		s.hands = s.hands[len(s.hands)-s.cfg.History:]
+	}
+	s.mu.Unlock()
+
+	if s.Record != nil {
+		if err := json.NewEncoder(s.Record).Encode(result); err != nil {
+			s.logger.Log("during", "WriteResults", "err", err)
+		}
+	}
+	return nil
+}
+
+// timeLimited gives a bot limit to answer each action and counts the times
+// it doesn't, and the errors it answers with.
+type timeLimited struct {
+	next     service.BotnaughtService
+	limit    time.Duration
+	timeouts *int64
+	errors   *int64
+}
+
+func (t timeLimited) Health(ctx context.Context) (service.HealthStatus, error) {
+	return t.next.Health(ctx)
+}
+
+func (t timeLimited) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	if t.limit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.limit)
+		defer cancel()
+	}
+	action, err := t.next.Action(ctx, g)
+	if ctx.Err() == context.DeadlineExceeded {
+		atomic.AddInt64(t.timeouts, 1)
+		return game.Action{}, fmt.Errorf("no action within %v", t.limit)
+	}
+	if err != nil {
+		atomic.AddInt64(t.errors, 1)
+	}
+	return action, err
+}
+
+// Handler serves /register and /results.
+func (s *Server) Handler() http.Handler {
+	m := http.NewServeMux()
+	m.HandleFunc("/register", s.serveRegister)
+	m.HandleFunc("/results", s.serveResults)
+	return m
+}
+
+// registerRequest is what client.Register sends.
+type registerRequest struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+}
+
+// registerResponse is what client.Register expects back.
+type registerResponse struct {
+	Err error `json:"err"`
+}
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) serveRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorWrapper{Error: "use POST"})
+		return
+	}
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorWrapper{Error: err.Error()})
+		return
+	}
+	if err := s.Register(req.Address, req.Name); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorWrapper{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, registerResponse{})
+}
+
+func (s *Server) serveResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorWrapper{Error: "use GET"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Results())
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
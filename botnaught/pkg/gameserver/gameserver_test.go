@@ -0,0 +1,112 @@
+package gameserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	client "github.com/gSchool/golang-curriculum-c-6/server/client/http"
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	log "github.com/go-kit/kit/log"
+	kithttp "github.com/go-kit/kit/transport/http"
+	endpoint "go-poker-project/Botnaught/botnaught/pkg/endpoint"
+	http2 "go-poker-project/Botnaught/botnaught/pkg/http"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+// slow takes delay over every action, then calls.
+type slow struct {
+	delay time.Duration
+}
+
+func (s slow) Health(ctx context.Context) (service.HealthStatus, error) {
+	return service.HealthStatus{Live: true, Ready: true}, nil
+}
+func (s slow) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+	}
+	return game.Action{SelectedAction: "call"}, nil
+}
+
+// serve starts svc behind the bot's real HTTP handler.
+func serve(svc service.BotnaughtService) *httptest.Server {
+	return httptest.NewServer(http2.NewHTTPHandler(endpoint.New(svc, nil), nil))
+}
+
+// register registers a bot at the server at url the way the bots do, with
+// the game server's own client, so that the wire format is the real one.
+func register(t *testing.T, url, address, name string) error {
+	c, err := client.New(url, map[string][]kithttp.ClientOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.Register(ctx, address, name)
+}
+
+func TestServer(t *testing.T) {
+	caller, err := service.NewBot(service.BotDefinition{Name: "Caller", Strategy: "call"}, service.DefaultBetParams(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Hands, cfg.ActionTimeout = 10, 50*time.Millisecond
+	server := New(cfg, log.NewNopLogger())
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	if err := register(t, ts.URL, "not a url", "Bad"); err == nil {
+		t.Error("registering a bad address succeeded")
+	}
+	callerServer, slowServer := serve(caller.Service), serve(slow{time.Second})
+	defer callerServer.Close()
+	defer slowServer.Close()
+	if err := register(t, ts.URL, callerServer.URL, "Caller"); err != nil {
+		t.Fatalf("registering Caller: %v", err)
+	}
+	if err := register(t, ts.URL, slowServer.URL, "Slow"); err != nil {
+		t.Fatalf("registering Slow: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(ts.URL + "/results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var results Results
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	if results.Played != 10 || len(results.Hands) != 10 {
+		t.Errorf("played %d hands, %d kept; want 10", results.Played, len(results.Hands))
+	}
+	chips := 0
+	for _, s := range results.Standings {
+		chips += s.Chips
+		switch s.Name {
+		case "Caller":
+			if s.Timeouts != 0 || s.Errors != 0 {
+				t.Errorf("Caller: %d timeouts, %d errors; want none", s.Timeouts, s.Errors)
+			}
+		case "Slow":
+			if s.Timeouts == 0 {
+				t.Error("Slow never timed out")
+			}
+		}
+	}
+	if chips != 0 {
+		t.Errorf("chips sum to %d, want 0", chips)
+	}
+}
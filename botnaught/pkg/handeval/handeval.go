@@ -0,0 +1,170 @@
+// Package handeval computes the effective hand strength metrics of Billings
+// et al.: hand strength against the opponents still in the hand, the
+// positive and negative potential of the next card and the effective hand
+// strength that combines them.
+//
+// Every opponent hand is enumerated, optionally weighted by how likely the
+// opponent is to hold it.
+package handeval
+
+import (
+	"math"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// Weighting gives the relative likelihood that an opponent holds a and b.
+// A nil Weighting weighs every hand alike.
+type Weighting interface {
+	Weight(a, b poker.Card) float64
+}
+
+// Strength is where a hand stands on a board of three to five cards.
+type Strength struct {
+	// HS is the chance of being ahead of one opponent now, ties counting
+	// half, and HSn that of being ahead of every opponent.
+	HS  float64
+	HSn float64
+	// PPot is the chance the next card takes us from behind to ahead and
+	// NPot from ahead to behind. Both are 0 on the river.
+	PPot float64
+	NPot float64
+	// EHS is HSn plus the chance of drawing ahead when behind.
+	EHS float64
+	// EHS2 is the mean of HS squared over the next card, which tells a
+	// hand that is steady apart from one whose strength is all potential.
+	EHS2 float64
+}
+
+// ahead, tied and behind index the potential tables.
+const (
+	ahead = iota
+	tied
+	behind
+)
+
+// Options says who Evaluate plays against and how hard it looks.
+type Options struct {
+	// Opponents is how many opponents are still in the hand; at least one.
+	Opponents int
+	// Weighting weighs the opponent hands; nil weighs them alike.
+	Weighting Weighting
+	// Samples caps how many opponent hands the potential is worked out
+	// against, taken at an even stride so that results are repeatable. 0
+	// works it out against all of them, some 50,000 evaluations on the flop.
+	Samples int
+}
+
+// Evaluate returns the Strength of hole on board. Boards of fewer than three
+// cards, and hands with no opponent holding left, are the zero Strength.
+func Evaluate(hole, board []poker.Card, opts Options) Strength {
+	if len(hole) != 2 || len(board) < 3 || len(board) > 5 {
+		return Strength{}
+	}
+	opponents, w := opts.Opponents, opts.Weighting
+	if opponents < 1 {
+		opponents = 1
+	}
+	live := cards.Without(hole, board)
+	stride := 1
+	if pairs := len(live) * (len(live) - 1) / 2; opts.Samples > 0 && pairs > opts.Samples {
+		stride = (pairs + opts.Samples - 1) / opts.Samples
+	}
+	ours := poker.Evaluate(append(append([]poker.Card(nil), hole...), board...))
+
+	// Our rank after each possible next card
+	var next []poker.Card
+	var oursNext []int32
+	if len(board) < 5 {
+		next = live
+		for _, c := range next {
+			oursNext = append(oursNext, poker.Evaluate(append(append(append([]poker.Card(nil), hole...), board...), c)))
+		}
+	}
+
+	var total [3]float64
+	var hp [3][3]float64
+	var hpTotal [3]float64
+	nextAhead := make([]float64, len(next))
+	nextTotal := make([]float64, len(next))
+	opp := make([]poker.Card, 0, 8)
+	pair := -1
+	for i := 0; i < len(live); i++ {
+		for j := i + 1; j < len(live); j++ {
+			a, b := live[i], live[j]
+			pair++
+			weight := 1.0
+			if w != nil {
+				weight = w.Weight(a, b)
+			}
+			if weight <= 0 {
+				continue
+			}
+			opp = append(append(opp[:0], a, b), board...)
+			now := compare(ours, poker.Evaluate(opp))
+			total[now] += weight
+			if pair%stride != 0 {
+				continue
+			}
+			for k, c := range next {
+				if c == a || c == b {
+					continue
+				}
+				later := compare(oursNext[k], poker.Evaluate(append(opp, c)))
+				hp[now][later] += weight
+				hpTotal[now] += weight
+				nextTotal[k] += weight
+				switch later {
+				case ahead:
+					nextAhead[k] += weight
+				case tied:
+					nextAhead[k] += weight / 2
+				}
+			}
+		}
+	}
+
+	var s Strength
+	sum := total[ahead] + total[tied] + total[behind]
+	if sum == 0 {
+		return s
+	}
+	s.HS = (total[ahead] + total[tied]/2) / sum
+	s.HSn = math.Pow(s.HS, float64(opponents))
+	if len(next) == 0 {
+		s.EHS, s.EHS2 = s.HSn, s.HS*s.HS
+		return s
+	}
+	if d := hpTotal[behind] + hpTotal[tied]/2; d > 0 {
+		s.PPot = (hp[behind][ahead] + hp[behind][tied]/2 + hp[tied][ahead]/2) / d
+	}
+	if d := hpTotal[ahead] + hpTotal[tied]/2; d > 0 {
+		s.NPot = (hp[ahead][behind] + hp[tied][behind]/2 + hp[ahead][tied]/2) / d
+	}
+	s.EHS = s.HSn + (1-s.HSn)*s.PPot
+	n := 0
+	for k := range next {
+		if nextTotal[k] > 0 {
+			hs := nextAhead[k] / nextTotal[k]
+			s.EHS2 += hs * hs
+			n++
+		}
+	}
+	if n > 0 {
+		s.EHS2 /= float64(n)
+	}
+	return s
+}
+
+// compare says whether our rank is ahead of, tied with or behind theirs;
+// lower ranks are better.
+func compare(ours, theirs int32) int {
+	switch {
+	case ours < theirs:
+		return ahead
+	case ours == theirs:
+		return tied
+	}
+	return behind
+}
@@ -0,0 +1,74 @@
+package handeval
+
+import (
+	"testing"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// acesOnly puts every opponent on a pair of aces.
+type acesOnly struct{}
+
+func (acesOnly) Weight(a, b poker.Card) float64 {
+	if cards.Rank(a) == 12 && cards.Rank(b) == 12 {
+		return 1
+	}
+	return 0
+}
+
+func TestEvaluate(t *testing.T) {
+	eval := func(hole, board string, opponents int, w Weighting) Strength {
+		return Evaluate(cards.MustParse(hole), cards.MustParse(board), Options{Opponents: opponents, Weighting: w})
+	}
+
+	nuts := eval("AhKh", "QhJhTh2c3d", 1, nil)
+	if nuts.HS != 1 || nuts.EHS != 1 || nuts.PPot != 0 || nuts.NPot != 0 {
+		t.Errorf("royal flush = %+v, want HS and EHS 1 and no potential", nuts)
+	}
+
+	top := eval("AsKd", "Kc7h2s", 1, nil)
+	if top.HS < .85 || top.NPot <= 0 || top.NPot > .2 {
+		t.Errorf("top pair top kicker = %+v, want HS above .85 and a small NPot", top)
+	}
+	if three := eval("AsKd", "Kc7h2s", 3, nil); three.HSn >= top.HSn || three.HS != top.HS {
+		t.Errorf("HSn against 3 = %.3f, against 1 = %.3f: want lower against more", three.HSn, top.HSn)
+	}
+
+	draw := eval("9h8h", "Kh7h2c", 1, nil)
+	if draw.PPot < .15 || draw.EHS <= draw.HS {
+		t.Errorf("flush draw = %+v, want PPot above .15 lifting EHS over HS", draw)
+	}
+	if draw.EHS2 <= draw.HS*draw.HS {
+		t.Errorf("flush draw EHS2 %.3f, want above HS squared %.3f", draw.EHS2, draw.HS*draw.HS)
+	}
+
+	if kings := eval("KsKd", "9c7h2s", 1, acesOnly{}); kings.HS != 0 {
+		t.Errorf("kings against aces = %+v, want HS 0", kings)
+	}
+	if none := eval("KsKd", "", 1, nil); none != (Strength{}) {
+		t.Errorf("preflop = %+v, want the zero Strength", none)
+	}
+}
+
+func BenchmarkEvaluateFlop(b *testing.B) {
+	hole, board := cards.MustParse("9h8h"), cards.MustParse("Kh7h2c")
+	for i := 0; i < b.N; i++ {
+		Evaluate(hole, board, Options{})
+	}
+}
+
+func TestSamples(t *testing.T) {
+	hole, board := cards.MustParse("9h8h"), cards.MustParse("Kh7h2c")
+	all := Evaluate(hole, board, Options{})
+	some := Evaluate(hole, board, Options{Samples: 200})
+	if some.HS != all.HS {
+		t.Errorf("sampled HS = %.3f, want the enumerated %.3f", some.HS, all.HS)
+	}
+	if d := some.PPot - all.PPot; d < -.05 || d > .05 {
+		t.Errorf("sampled PPot = %.3f, want within .05 of %.3f", some.PPot, all.PPot)
+	}
+	if again := Evaluate(hole, board, Options{Samples: 200}); again != some {
+		t.Errorf("sampling twice gave %+v and %+v", some, again)
+	}
+}
@@ -0,0 +1,237 @@
+// Package handlog defines the lines of game.Game.HandLog as BotNaught's own
+// tools write them. Each line is one event of the game, oldest first:
+//
+//	-- hand 12 button Jimmy
+//	Jimmy small_blind 1
+//	Guido big_blind 2
+//	Vinnie raise 6
+//	Jimmy fold
+//	Guido call 6
+//	-- flop Ts 3h 7c
+//	Guido check
+//	Vinnie raise 8
+//	Guido call 8
+//	...
+//	Vinnie shows As Kd
+//	Vinnie wins 34
+//
+// Amounts on betting lines are the player's total commitment for the street
+// after acting, so "raise 8" is a raise to 8.
+package handlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// Verbs used on betting lines.
+const (
+	SmallBlind = "small_blind"
+	BigBlind   = "big_blind"
+	Fold       = "fold"
+	Check      = "check"
+	Call       = "call"
+	Raise      = "raise"
+)
+
+// Verbs used on showdown lines.
+const (
+	ShowsVerb = "shows"
+	WinsVerb  = "wins"
+)
+
+// Hand starts a hand.
+func Hand(number int, button string) string {
+	return "-- hand " + strconv.Itoa(number) + " button " + button
+}
+
+// Street starts the flop, turn or river with the cards it dealt.
+func Street(name string, dealt []poker.Card) string {
+	return "-- " + name + " " + cards.String(dealt)
+}
+
+// Bet records a betting action; to is ignored for folds and checks.
+func Bet(player, verb string, to int) string {
+	if verb == Fold || verb == Check {
+		return player + " " + verb
+	}
+	return player + " " + verb + " " + strconv.Itoa(to)
+}
+
+// Shows records hole cards revealed at showdown.
+func Shows(player string, hole []poker.Card) string {
+	return player + " " + ShowsVerb + " " + cards.String(hole)
+}
+
+// Wins records chips taken from the pot.
+func Wins(player string, amount int) string {
+	return player + " " + WinsVerb + " " + strconv.Itoa(amount)
+}
+
+// Kind tells the lines apart.
+type Kind int
+
+// Kinds of line.
+const (
+	HandLine Kind = iota + 1
+	StreetLine
+	BetLine
+	ShowsLine
+	WinsLine
+)
+
+// Line is a parsed line.
+type Line struct {
+	Kind Kind
+	// Player is the acting player, or the button on a hand line.
+	Player string
+	// Verb is the betting verb of a bet line.
+	Verb string
+	// Street is the street a street line starts.
+	Street string
+	// Number is the hand number of a hand line.
+	Number int
+	// Amount is the street commitment of a bet line, or the chips won.
+	Amount int
+	// Cards are the cards dealt on a street line or shown on a shows line.
+	Cards []poker.Card
+}
+
+// Parse reads a line written by this package.
+func Parse(line string) (Line, error) {
+	f := strings.Fields(line)
+	if len(f) < 2 {
+		return Line{}, fmt.Errorf("handlog: short line %q", line)
+	}
+	if f[0] == "--" {
+		if f[1] == "hand" {
+			if len(f) != 5 || f[3] != "button" {
+				return Line{}, fmt.Errorf("handlog: bad hand line %q", line)
+			}
+			n, err := strconv.Atoi(f[2])
+			if err != nil {
+				return Line{}, fmt.Errorf("handlog: bad hand line %q: %v", line, err)
+			}
+			return Line{Kind: HandLine, Number: n, Player: f[4]}, nil
+		}
+		dealt, err := cards.Parse(strings.Join(f[2:], " "))
+		if err != nil {
+			return Line{}, fmt.Errorf("handlog: bad street line %q: %v", line, err)
+		}
+		return Line{Kind: StreetLine, Street: f[1], Cards: dealt}, nil
+	}
+
+	l := Line{Player: f[0], Verb: f[1]}
+	switch f[1] {
+	case ShowsVerb:
+		shown, err := cards.Parse(strings.Join(f[2:], " "))
+		if err != nil {
+			return Line{}, fmt.Errorf("handlog: bad shows line %q: %v", line, err)
+		}
+		l.Kind, l.Cards = ShowsLine, shown
+		return l, nil
+	case WinsVerb:
+		l.Kind = WinsLine
+	case Fold, Check:
+		l.Kind = BetLine
+		return l, nil
+	case SmallBlind, BigBlind, Call, Raise:
+		l.Kind = BetLine
+	default:
+		return Line{}, fmt.Errorf("handlog: unknown verb in %q", line)
+	}
+	if len(f) != 3 {
+		return Line{}, fmt.Errorf("handlog: want an amount in %q", line)
+	}
+	amount, err := strconv.Atoi(f[2])
+	if err != nil {
+		return Line{}, fmt.Errorf("handlog: bad amount in %q: %v", line, err)
+	}
+	l.Amount = amount
+	return l, nil
+}
+
+// Current returns the parsed lines of the last hand in log, starting with its
+// hand line. Lines that don't parse are skipped.
+func Current(log []string) []Line {
+	start := 0
+	for i := len(log) - 1; i >= 0; i-- {
+		if strings.HasPrefix(log[i], "-- hand ") {
+			start = i
+			break
+		}
+	}
+	var lines []Line
+	for _, raw := range log[start:] {
+		if l, err := Parse(raw); err == nil {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// Hands splits log into hands, each starting with its hand line. Lines before
+// the first hand line and lines that don't parse are skipped.
+func Hands(log []string) [][]Line {
+	var hands [][]Line
+	for _, raw := range log {
+		l, err := Parse(raw)
+		if err != nil {
+			continue
+		}
+		if l.Kind == HandLine {
+			hands = append(hands, nil)
+		}
+		if len(hands) > 0 {
+			hands[len(hands)-1] = append(hands[len(hands)-1], l)
+		}
+	}
+	return hands
+}
+
+// Finished reports whether hand, the parsed lines of one hand, has been paid
+// out.
+func Finished(hand []Line) bool {
+	for _, l := range hand {
+		if l.Kind == WinsLine {
+			return true
+		}
+	}
+	return false
+}
+
+// Seen remembers the last hand read from each game's log, so that a log read
+// again as it grows yields each finished hand once. The zero value has seen
+// nothing; a Seen is not safe for concurrent use.
+type Seen struct {
+	last map[string]int // game ID to the last hand number seen
+}
+
+// Finished returns the finished hands of log, from the game gameID, that
+// come after the last one seen, and marks them seen. Hands still being
+// played are left for a later call.
+func (s *Seen) Finished(gameID string, log []string) [][]Line {
+	var hands [][]Line
+	for _, hand := range Hands(log) {
+		if hand[0].Number <= s.last[gameID] || !Finished(hand) {
+			continue
+		}
+		s.Mark(gameID, hand[0].Number)
+		hands = append(hands, hand)
+	}
+	return hands
+}
+
+// Mark marks the hands of gameID up to number seen.
+func (s *Seen) Mark(gameID string, number int) {
+	if s.last == nil {
+		s.last = map[string]int{}
+	}
+	if number > s.last[gameID] {
+		s.last[gameID] = number
+	}
+}
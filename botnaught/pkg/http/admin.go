@@ -0,0 +1,189 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+// NewAdminHandler serves the admin API for bots under prefix, and 404 for
+// any other path it is handed. Every request must carry
+// "Authorization: Bearer <token>".
+//
+//	GET      prefix                        every bot and its state
+//	GET      prefix/{bot}/games            the bot's active games
+//	GET      prefix/{bot}/games/{id}       the last game.Game seen in a game
+//	GET, PUT prefix/{bot}/params           Bet parameters; PUT changes the fields given
+//	GET, PUT prefix/{bot}/passive          {"passive": true} checks or folds every hand
+//	POST     prefix/{bot}/log/rotate       moves the decision log aside
+//	GET      prefix/{bot}/showdowns        per-opponent showdown stats
+func NewAdminHandler(prefix, token string, bots []*service.Bot) http.Handler {
+	byName := map[string]*service.Bot{}
+	for _, bot := range bots {
+		byName[bot.Definition.Name] = bot
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only prefix and the paths below it: "/admin/botsX" is not bot X
+		if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			writeError(w, http.StatusNotFound, errors.New("no such admin route"))
+			return
+		}
+		if !authorized(r, token) {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or wrong admin token"))
+			return
+		}
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"), "/")
+		if parts[0] == "" {
+			if r.Method != http.MethodGet {
+				writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+				return
+			}
+			all := []botState{}
+			for _, bot := range bots {
+				all = append(all, stateOf(bot))
+			}
+			writeJSON(w, http.StatusOK, all)
+			return
+		}
+		bot, ok := byName[parts[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("no bot named "+parts[0]))
+			return
+		}
+		switch route := strings.Join(parts[1:], "/"); {
+		case route == "" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, stateOf(bot))
+		case route == "games" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, bot.Games.Summaries())
+		case len(parts) == 3 && parts[1] == "games" && r.Method == http.MethodGet:
+			g, ok := bot.Games.Last(parts[2])
+			if !ok {
+				writeError(w, http.StatusNotFound, errors.New("no active game "+parts[2]))
+				return
+			}
+			writeJSON(w, http.StatusOK, g)
+		case route == "params":
+			serveParams(w, r, bot)
+		case route == "passive":
+			servePassive(w, r, bot)
+		case route == "log/rotate" && r.Method == http.MethodPost:
+			if bot.Log == nil {
+				writeError(w, http.StatusNotFound, errors.New(bot.Definition.Name+" keeps no decision log"))
+				return
+			}
+			rotated, err := bot.Log.Rotate()
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"rotated_to": rotated})
+		case route == "showdowns" && r.Method == http.MethodGet:
+			if bot.Showdowns == nil {
+				writeError(w, http.StatusNotFound, errors.New(bot.Definition.Name+" collects no showdowns"))
+				return
+			}
+			writeJSON(w, http.StatusOK, bot.Showdowns.All())
+		default:
+			writeError(w, http.StatusNotFound, errors.New("no such admin route"))
+		}
+	})
+}
+
+// botState is a bot's entry in the admin bot listing.
+type botState struct {
+	Name          string `json:"name"`
+	Strategy      string `json:"strategy"`
+	Passive       bool   `json:"passive"`
+	ActiveGames   int    `json:"active_games"`
+	ParamsVersion int64  `json:"params_version,omitempty"`
+	DecisionLog   string `json:"decision_log,omitempty"`
+	// DecisionCache is nil unless the strategy caches decisions.
+	DecisionCache *service.CacheStats `json:"decision_cache,omitempty"`
+}
+
+func stateOf(bot *service.Bot) botState {
+	s := botState{
+		Name:        bot.Definition.Name,
+		Strategy:    bot.Definition.Strategy,
+		Passive:     bot.Passive.On(),
+		ActiveGames: len(bot.Games.Active()),
+	}
+	if bot.Params != nil {
+		s.ParamsVersion = bot.Params.Load().Version
+	}
+	if bot.Log != nil {
+		s.DecisionLog = bot.Log.Path()
+	}
+	if bot.Cache != nil {
+		stats := bot.Cache.Stats()
+		s.DecisionCache = &stats
+	}
+	return s
+}
+
+func serveParams(w http.ResponseWriter, r *http.Request, bot *service.Bot) {
+	if bot.Params == nil {
+		writeError(w, http.StatusNotFound, errors.New(bot.Definition.Name+" has no Bet parameters"))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, bot.Params.Load())
+	case http.MethodPut:
+		params := bot.Params.Load().Params
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		stored, err := bot.Params.Store(params)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, stored)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+type passiveState struct {
+	Passive bool `json:"passive"`
+}
+
+func servePassive(w http.ResponseWriter, r *http.Request, bot *service.Bot) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req passiveState
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		bot.Passive.Set(req.Passive)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	writeJSON(w, http.StatusOK, passiveState{Passive: bot.Passive.On()})
+}
+
+// authorized reports whether r carries the admin token.
+func authorized(r *http.Request, token string) bool {
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorWrapper{Error: err.Error()})
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+func TestAdminHandler(t *testing.T) {
+	bot, err := service.NewBot(service.BotDefinition{Name: "AdminBot", Strategy: "basic"}, service.DefaultBetParams(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewAdminHandler("/admin/bots", "secret", []*service.Bot{bot})
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		if w := do("GET", "/admin/bots", token, ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d, want 401", token, w.Code)
+		}
+	}
+	if w := do("GET", "/admin/bots/Nobody/games", "secret", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown bot: status %d, want 404", w.Code)
+	}
+	for _, path := range []string{"/admin/botsAdminBot/params", "/admin/other", "/admin/"} {
+		if w := do("GET", path, "secret", ""); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status %d, want 404", path, w.Code)
+		}
+	}
+	if w := do("GET", "/admin/bots/", "secret", ""); w.Code != http.StatusOK {
+		t.Errorf("GET /admin/bots/: status %d, want 200", w.Code)
+	}
+
+	if w := do("PUT", "/admin/bots/AdminBot/passive", "secret", `{"passive": true}`); w.Code != http.StatusOK || !bot.Passive.On() {
+		t.Errorf("PUT passive: status %d, passive %v", w.Code, bot.Passive.On())
+	}
+
+	w := do("PUT", "/admin/bots/AdminBot/params", "secret", `{"all_in_strength": 0.9}`)
+	var stored service.VersionedParams
+	if err := json.NewDecoder(w.Body).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || stored.Params.AllInStrength != 0.9 || stored.Params.PreflopRaise != 0.2 || stored.Version != 2 {
+		t.Errorf("PUT params: status %d, stored %+v", w.Code, stored)
+	}
+	if w := do("PUT", "/admin/bots/AdminBot/params", "secret", `{"all_in_strength": 3}`); w.Code != http.StatusBadRequest {
+		t.Errorf("PUT invalid params: status %d, want 400", w.Code)
+	}
+}
@@ -6,22 +6,49 @@ import (
 	"errors"
 	http1 "github.com/go-kit/kit/transport/http"
 	endpoint "go-poker-project/Botnaught/botnaught/pkg/endpoint"
+	"io"
 	"net/http"
 )
 
-// makeHealthHandler creates the handler logic
+// makeHealthHandler creates the handler logic. /health always answers with
+// the full status; /health/live and /health/ready answer 503 when the bot is
+// not live or not ready so orchestration can act on the status code alone.
 func makeHealthHandler(m *http.ServeMux, endpoints endpoint.Endpoints, options []http1.ServerOption) {
 	m.Handle("/health", http1.NewServer(endpoints.HealthEndpoint, decodeHealthRequest, encodeHealthResponse, options...))
+	m.Handle("/health/live", http1.NewServer(endpoints.HealthEndpoint, decodeHealthRequest, encodeLiveResponse, options...))
+	m.Handle("/health/ready", http1.NewServer(endpoints.HealthEndpoint, decodeHealthRequest, encodeReadyResponse, options...))
 }
 
 // decodeHealthRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body.
+// JSON-encoded request from the HTTP request body. Probes usually send no
+// body at all, which is accepted as an empty request.
 func decodeHealthRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	req := endpoint.HealthRequest{}
 	err := json.NewDecoder(r.Body).Decode(&req)
+	if err == io.EOF {
+		err = nil
+	}
 	return req, err
 }
 
+// encodeLiveResponse encodes the health status, answering 503 unless live.
+func encodeLiveResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if r, ok := response.(endpoint.HealthResponse); ok && r.Err == nil && !r.Status.Live {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	return encodeHealthResponse(ctx, w, response)
+}
+
+// encodeReadyResponse encodes the health status, answering 503 unless ready.
+func encodeReadyResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if r, ok := response.(endpoint.HealthResponse); ok && r.Err == nil && !r.Status.Ready {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	return encodeHealthResponse(ctx, w, response)
+}
+
 // encodeHealthResponse is a transport/http.EncodeResponseFunc that encodes
 // the response as JSON to the response writer
 func encodeHealthResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
@@ -0,0 +1,119 @@
+// Package iso maps cards to a canonical form under suit isomorphism: two
+// states that differ only by a renaming of the suits, like AsKs on 2s7s9d
+// and AhKh on 2h7h9c, or only by the order of cards within a group, have
+// the same form and hash. Lookup tables, caches and training data keyed by
+// the hash share entries across equivalent states.
+package iso
+
+import (
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// permutations lists the 24 ways of renaming the four suits.
+var permutations = func() [][4]int {
+	var perms [][4]int
+	var permute func(p [4]int, n int)
+	permute = func(p [4]int, n int) {
+		if n == 4 {
+			perms = append(perms, p)
+			return
+		}
+		for i := n; i < 4; i++ {
+			p[n], p[i] = p[i], p[n]
+			permute(p, n+1)
+			p[n], p[i] = p[i], p[n]
+		}
+	}
+	permute([4]int{0, 1, 2, 3}, 0)
+	return perms
+}()
+
+// Form is the canonical form of groups of cards.
+type Form struct {
+	// Groups are the renamed cards of each group, highest first.
+	Groups [][]poker.Card
+	// Suits[s] is the suit, in the order of cards.Suits, that suit s was
+	// renamed to.
+	Suits [4]int
+	key   []int
+}
+
+// Canonical returns the canonical form of groups of cards, e.g. the hole
+// cards then the board. Cards can't move between groups, so pass the flop,
+// turn and river separately when it matters which street a card came on.
+// Of the 24 renamings of the suits, the canonical one is the one whose
+// sorted groups, compared card by card, are smallest.
+func Canonical(groups ...[]poker.Card) Form {
+	var best Form
+	key := make([]int, 0, 16)
+	for n, perm := range permutations {
+		key = encode(key[:0], groups, perm)
+		if n == 0 || less(key, best.key) {
+			best.key = append(best.key[:0], key...)
+			best.Suits = perm
+		}
+	}
+	best.Groups = make([][]poker.Card, len(groups))
+	i := 0
+	for g := range groups {
+		best.Groups[g] = make([]poker.Card, len(groups[g]))
+		for j := range groups[g] {
+			best.Groups[g][j] = cards.FromIndex(best.key[i] - 1)
+			i++
+		}
+		i++ // the separator
+	}
+	return best
+}
+
+// encode appends the renamed card indexes of each group, plus one and
+// sorted highest first, each group followed by a zero.
+func encode(key []int, groups [][]poker.Card, perm [4]int) []int {
+	for _, g := range groups {
+		start := len(key)
+		for _, c := range g {
+			i := cards.Rank(c)*len(cards.Suits) + perm[cards.Suit(c)] + 1
+			key = append(key, i)
+			for j := len(key) - 1; j > start && key[j-1] < key[j]; j-- {
+				key[j-1], key[j] = key[j], key[j-1]
+			}
+		}
+		key = append(key, 0)
+	}
+	return key
+}
+
+func less(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// Hash returns a 64-bit hash of the form. Forms of up to ten cards and
+// separators, such as two hole cards and a board of up to five, pack into it
+// six bits each and never collide; longer ones are mixed with FNV-1a.
+func (f Form) Hash() uint64 {
+	if len(f.key) <= 10 {
+		// The leading 1 keeps empty groups at the front from vanishing.
+		h := uint64(1)
+		for _, i := range f.key {
+			h = h<<6 | uint64(i)
+		}
+		return h
+	}
+	h := uint64(14695981039346656037)
+	for _, i := range f.key {
+		h ^= uint64(i)
+		h *= 1099511628211
+	}
+	return h
+}
+
+// Hash is the hash of the canonical form of hole cards and a board.
+func Hash(hole, board []poker.Card) uint64 {
+	return Canonical(hole, board).Hash()
+}
@@ -0,0 +1,71 @@
+package iso
+
+import (
+	"testing"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		name          string
+		hole1, board1 string
+		hole2, board2 string
+		same          bool
+	}{
+		{"suits renamed", "AsKs", "2s7s9d", "AhKh", "2h7h9c", true},
+		{"cards reordered", "KsAs", "9d2s7s", "AsKs", "2s7s9d", true},
+		{"every suit renamed", "AsKd", "2h7c", "AdKc", "2s7h", true},
+		{"suited against offsuit", "AsKs", "", "AsKh", "", false},
+		{"flush draw against none", "AsKs", "2s7s9d", "AsKs", "2s7d9c", false},
+		{"card moved to the board", "AsKs", "2s7s9d", "As2s", "Ks7s9d", false},
+		{"turn", "AsKs", "2s7s9d4h", "AsKs", "2s7s9d", false},
+	}
+	for _, tt := range tests {
+		h1 := Hash(cards.MustParse(tt.hole1), cards.MustParse(tt.board1))
+		h2 := Hash(cards.MustParse(tt.hole2), cards.MustParse(tt.board2))
+		if (h1 == h2) != tt.same {
+			t.Errorf("%s: hashes %x and %x, want same = %v", tt.name, h1, h2, tt.same)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	hole, board := cards.MustParse("Kh7d"), cards.MustParse("2d7hQc")
+	f := Canonical(hole, board)
+	if len(f.Groups) != 2 || len(f.Groups[0]) != 2 || len(f.Groups[1]) != 3 {
+		t.Fatalf("groups %v", f.Groups)
+	}
+	// Renaming the original cards with Suits gives the canonical cards.
+	rename := func(cs []poker.Card) map[poker.Card]bool {
+		m := map[poker.Card]bool{}
+		for _, c := range cs {
+			m[cards.FromIndex(cards.Rank(c)*4+f.Suits[cards.Suit(c)])] = true
+		}
+		return m
+	}
+	for g, orig := range [][]poker.Card{hole, board} {
+		want := rename(orig)
+		for _, c := range f.Groups[g] {
+			if !want[c] {
+				t.Errorf("group %d: %v is not a renamed original card", g, c)
+			}
+		}
+	}
+	// Empty leading groups still tell forms apart.
+	if Canonical(nil, hole).Hash() == Canonical(hole).Hash() {
+		t.Error("an empty leading group hashes like none")
+	}
+	// All 1326 hole card combinations come to the 169 kinds of hand.
+	all := cards.All()
+	kinds := map[uint64]bool{}
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			kinds[Hash([]poker.Card{all[i], all[j]}, nil)] = true
+		}
+	}
+	if len(kinds) != 169 {
+		t.Errorf("%d kinds of hole cards, want 169", len(kinds))
+	}
+}
@@ -0,0 +1,259 @@
+// Package madehand says what hole cards contribute to the made hand on the
+// board: top pair or a pair the board made, a set or trips, two pair from
+// one hole card or both. The evaluator's rank alone can't tell these apart.
+package madehand
+
+import (
+	"fmt"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// Class is what the hole cards make, weakest first.
+type Class int
+
+const (
+	// PlayingTheBoard is a made hand that is all the board's: our cards add
+	// kickers at most.
+	PlayingTheBoard Class = iota
+	HighCard
+	// Underpair is a pocket pair below every board card and BottomPair a
+	// hole card pairing the lowest one.
+	Underpair
+	BottomPair
+	// MiddlePair pairs a board card between the top and bottom ones, or is
+	// a pocket pair between them.
+	MiddlePair
+	TopPair
+	// Overpair is a pocket pair above every board card.
+	Overpair
+	// TwoPairOne is two pair from one hole card and a paired board,
+	// TwoPairBoth from both hole cards pairing the board.
+	TwoPairOne
+	TwoPairBoth
+	// Trips is one hole card matching a pair on the board, Set a pocket
+	// pair matching a board card.
+	Trips
+	Set
+	Straight
+	Flush
+	FullHouse
+	Quads
+	StraightFlush
+)
+
+var classNames = [...]string{
+	"playing the board", "high card", "underpair", "bottom pair", "middle pair", "top pair", "overpair",
+	"two pair with one hole card", "two pair with both hole cards", "trips", "set",
+	"straight", "flush", "full house", "quads", "straight flush",
+}
+
+func (c Class) String() string {
+	return classNames[c]
+}
+
+// Hand is a classified hand.
+type Hand struct {
+	Class Class
+	// Kicker is the rank, 0 for a deuce to 12 for an ace, of the hole card
+	// that doesn't make a one-pair or trips hand, or -1.
+	Kicker int
+	// KickerGap is how many unpaired ranks not on the board beat the
+	// kicker: 0 is the best kicker there can be.
+	KickerGap int
+}
+
+// String describes the hand, e.g. "top pair, kicker K (best)".
+func (h Hand) String() string {
+	if h.Kicker < 0 {
+		return h.Class.String()
+	}
+	gap := "best"
+	if h.KickerGap > 0 {
+		gap = fmt.Sprintf("%d better", h.KickerGap)
+	}
+	return fmt.Sprintf("%s, kicker %c (%s)", h.Class, cards.Ranks[h.Kicker], gap)
+}
+
+// Classify says what two hole cards make on a board of three to five cards.
+func Classify(hole, board []poker.Card) Hand {
+	h := Hand{Kicker: -1}
+	if len(hole) != 2 || len(board) < 3 {
+		return h
+	}
+	all := append(append([]poker.Card(nil), hole...), board...)
+	rank := poker.Evaluate(all)
+	if len(board) == 5 && poker.Evaluate(board) == rank {
+		return h
+	}
+
+	var onBoard [13]int
+	top, bottom := -1, 13
+	for _, c := range board {
+		r := cards.Rank(c)
+		onBoard[r]++
+		if r > top {
+			top = r
+		}
+		if r < bottom {
+			bottom = r
+		}
+	}
+	a, b := cards.Rank(hole[0]), cards.Rank(hole[1])
+	pocket := a == b
+	boardPaired := false
+	for _, n := range onBoard {
+		if n >= 2 {
+			boardPaired = true
+		}
+	}
+	// kicker sets the kicker to the hole card other than the one of rank r.
+	kicker := func(r int) {
+		k := a
+		if k == r {
+			k = b
+		}
+		h.Kicker = k
+		for above := k + 1; above < 13; above++ {
+			if onBoard[above] == 0 && above != r {
+				h.KickerGap++
+			}
+		}
+	}
+	pairClass := func(r int) Class {
+		switch {
+		case r == top:
+			return TopPair
+		case r == bottom:
+			return BottomPair
+		}
+		return MiddlePair
+	}
+	pocketClass := func() Class {
+		switch {
+		case a > top:
+			return Overpair
+		case a < bottom:
+			return Underpair
+		}
+		return MiddlePair
+	}
+
+	switch poker.RankClass(rank) {
+	case 1:
+		h.Class = StraightFlush
+	case 2:
+		h.Class = Quads
+	case 3:
+		h.Class = FullHouse
+	case 4:
+		h.Class = Flush
+	case 5:
+		h.Class = Straight
+	case 6:
+		switch {
+		case pocket && onBoard[a] > 0:
+			h.Class = Set
+		case onBoard[a] >= 2:
+			h.Class = Trips
+			kicker(a)
+		case onBoard[b] >= 2:
+			h.Class = Trips
+			kicker(b)
+		}
+	case 7:
+		switch {
+		case pocket:
+			h.Class = pocketClass()
+		case onBoard[a] > 0 && onBoard[b] > 0:
+			h.Class = TwoPairBoth
+		case (onBoard[a] > 0 || onBoard[b] > 0) && boardPaired:
+			h.Class = TwoPairOne
+		}
+	case 8:
+		switch {
+		case pocket:
+			h.Class = pocketClass()
+		case onBoard[a] > 0:
+			h.Class = pairClass(a)
+			kicker(a)
+		case onBoard[b] > 0:
+			h.Class = pairClass(b)
+			kicker(b)
+		}
+	case 9:
+		h.Class = HighCard
+	}
+	return h
+}
+
+// Lead is how many ranks, on the evaluator's scale, hole cards put us above
+// what the board gives everyone: our rank against the board filled out
+// with the lowest cards that neither pair it nor make a straight or flush.
+// Hands that are high card or all the board's lead by 0.
+func Lead(hole, board []poker.Card) int32 {
+	h := Classify(hole, board)
+	if h.Class <= HighCard || len(board) > 5 {
+		return 0
+	}
+	baseline := blankRank(board)
+	if baseline < 0 {
+		return 0
+	}
+	return baseline - poker.Evaluate(append(append([]poker.Card(nil), hole...), board...))
+}
+
+// blankRank returns the rank of the board filled out to five cards with
+// blanks, or -1 when no blanks can be found.
+func blankRank(board []poker.Card) int32 {
+	need := 5 - len(board)
+	if need == 0 {
+		return poker.Evaluate(board)
+	}
+	var onBoard [13]bool
+	for _, c := range board {
+		onBoard[cards.Rank(c)] = true
+	}
+	var free []int
+	for r := 0; r < 13; r++ {
+		if !onBoard[r] {
+			free = append(free, r)
+		}
+	}
+	for i := 0; i < len(free); i++ {
+		for j := i + 1; j < len(free); j++ {
+			if need == 1 && j > i+1 {
+				break
+			}
+			filled := fill(board, need, free[i], free[j])
+			switch poker.RankClass(poker.Evaluate(filled)) {
+			case 1, 4, 5:
+				continue
+			}
+			return poker.Evaluate(filled)
+		}
+	}
+	return -1
+}
+
+// fill adds need cards of ranks r1 and r2 to board in suits it has fewest
+// of.
+func fill(board []poker.Card, need, r1, r2 int) []poker.Card {
+	var count [4]int
+	for _, c := range board {
+		count[cards.Suit(c)]++
+	}
+	filled := append([]poker.Card(nil), board...)
+	for n, r := range []int{r1, r2}[:need] {
+		best := 0
+		for s := range count {
+			if count[s] < count[best] {
+				best = s
+			}
+		}
+		count[best] += 10 + n
+		filled = append(filled, cards.FromIndex(r*len(cards.Suits)+best))
+	}
+	return filled
+}
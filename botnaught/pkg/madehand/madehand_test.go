@@ -0,0 +1,59 @@
+package madehand
+
+import (
+	"testing"
+
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		hole, board string
+		want        Hand
+		str         string
+	}{
+		{"AsKd", "Kc7h2s", Hand{TopPair, 12, 0}, "top pair, kicker A (best)"},
+		{"KsQd", "Kc7h2s", Hand{TopPair, 10, 1}, "top pair, kicker Q (1 better)"},
+		{"As7d", "Kc7h2s", Hand{MiddlePair, 12, 0}, "middle pair, kicker A (best)"},
+		{"Qs2d", "Kc7h2s", Hand{BottomPair, 10, 1}, "bottom pair, kicker Q (1 better)"},
+		{"AsAd", "Kc7h2s", Hand{Overpair, -1, 0}, "overpair"},
+		{"9s9d", "Kc7h2s", Hand{MiddlePair, -1, 0}, "middle pair"},
+		{"3s3d", "Kc7h4s", Hand{Underpair, -1, 0}, "underpair"},
+		{"AsQd", "Kc7h2s", Hand{HighCard, -1, 0}, "high card"},
+		{"AsQd", "7c7h2s", Hand{PlayingTheBoard, -1, 0}, "playing the board"},
+		{"Ks7d", "Kc7h2s", Hand{TwoPairBoth, -1, 0}, "two pair with both hole cards"},
+		{"Ks3d", "Kc7h7s", Hand{TwoPairOne, -1, 0}, "two pair with one hole card"},
+		{"AsAd", "Kc7h7s", Hand{Overpair, -1, 0}, "overpair"},
+		{"7s7d", "Kc7h2s", Hand{Set, -1, 0}, "set"},
+		{"As7d", "Kc7h7s", Hand{Trips, 12, 0}, "trips, kicker A (best)"},
+		{"9s8d", "Tc7h2s", Hand{HighCard, -1, 0}, "high card"},
+		{"9s8d", "Tc7h6s", Hand{Straight, -1, 0}, "straight"},
+		{"AhKd", "2c3c4c5c6c", Hand{PlayingTheBoard, -1, 0}, "playing the board"},
+		{"7cKd", "2c3c4c5c6c", Hand{StraightFlush, -1, 0}, "straight flush"},
+	}
+	for _, tt := range tests {
+		got := Classify(cards.MustParse(tt.hole), cards.MustParse(tt.board))
+		if got != tt.want {
+			t.Errorf("Classify(%s, %s) = %+v, want %+v", tt.hole, tt.board, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("Classify(%s, %s).String() = %q, want %q", tt.hole, tt.board, got.String(), tt.str)
+		}
+	}
+}
+
+func TestLead(t *testing.T) {
+	board := cards.MustParse("Kc7h2s")
+	top := Lead(cards.MustParse("AsKd"), board)
+	weak := Lead(cards.MustParse("KsQd"), board)
+	middle := Lead(cards.MustParse("As7d"), board)
+	if !(top > weak && weak > middle && middle > 0) {
+		t.Errorf("leads top %d, weak kicker %d, middle %d: want decreasing and positive", top, weak, middle)
+	}
+	if lead := Lead(cards.MustParse("AsQd"), cards.MustParse("7c7h2s")); lead != 0 {
+		t.Errorf("playing the board leads by %d, want 0", lead)
+	}
+	if lead := Lead(cards.MustParse("AsQd"), board); lead != 0 {
+		t.Errorf("high card leads by %d, want 0", lead)
+	}
+}
@@ -0,0 +1,249 @@
+// Package narrow puts opponents on hand ranges. Each starts from a prior set
+// by their position and is narrowed by Bayes' rule after every check, call
+// and raise of the current hand: a combo's weight is multiplied by how likely
+// a hand of its strength is to have taken the action.
+package narrow
+
+import (
+	"math"
+	"sort"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	handlog "go-poker-project/Botnaught/botnaught/pkg/handlog"
+	ranges "go-poker-project/Botnaught/botnaught/pkg/ranges"
+)
+
+// Outside is the weight the prior leaves on hands outside a position's
+// opening range, for the ones who play them anyway.
+const Outside = .05
+
+// Prior returns the range of a player position seats after the button at a
+// table of players: the strongest hands, by ChenPercentile, that the
+// position opens with, and Outside for the rest. The button and the blinds
+// play wider than the early seats.
+func Prior(position, players int) ranges.Range {
+	open := openShare(position, players)
+	var r ranges.Range
+	for i := 0; i < ranges.NumCombos; i++ {
+		w := Outside
+		if preflop[i] >= 1-open {
+			w = 1
+		}
+		r.SetAt(i, w)
+	}
+	return r
+}
+
+// openShare is the share of hands a position opens with.
+func openShare(position, players int) float64 {
+	if players <= 2 {
+		return .8 // heads up the button and big blind play most hands
+	}
+	switch position % players {
+	case 0:
+		return .45 // button
+	case 1:
+		return .35 // small blind
+	case 2:
+		return .6 // big blind, who has already paid
+	}
+	// The rest open wider the closer they sit to the button
+	early := players - position
+	switch {
+	case early <= 1:
+		return .3
+	case early == 2:
+		return .22
+	}
+	return .15
+}
+
+// reraise stands for a raise over a raise made earlier in the street: a
+// 3-bet, or a check-raise.
+const reraise = "reraise"
+
+// Likelihoods of each action for a hand of strength s, 0 for the weakest
+// hand and 1 for the strongest.
+func likelihood(verb string, s float64) float64 {
+	switch verb {
+	case handlog.Raise:
+		// Mostly strong hands, with some bluffs
+		return .05 + .95*s*s*s*s
+	case reraise:
+		// Raising a bet, as in a check-raise, is stronger still
+		return .01 + .99*math.Pow(s, 16)
+	case handlog.Call:
+		return .1 + .9*s
+	case handlog.Check:
+		// Strong hands check now and then to trap
+		return 1 - .5*s*s
+	}
+	return 1
+}
+
+// Hand returns the ranges of every opponent of me still in the current hand
+// of log, seated in the order of seats. Combos holding one of the dead
+// cards, our hole cards and the board, are removed.
+func Hand(log []string, seats []string, me string, dead ...[]poker.Card) map[string]*ranges.Range {
+	lines := handlog.Current(log)
+	if len(lines) == 0 || lines[0].Kind != handlog.HandLine {
+		return nil
+	}
+	button := 0
+	for i, name := range seats {
+		if name == lines[0].Player {
+			button = i
+		}
+	}
+	held := map[string]*ranges.Range{}
+	for i, name := range seats {
+		if name == me {
+			continue
+		}
+		r := Prior((i-button+len(seats))%len(seats), len(seats))
+		r.Remove(dead...)
+		held[name] = &r
+	}
+
+	strength := preflop[:]
+	var board []poker.Card
+	bet := false // whether anyone has raised this street
+	for _, l := range lines[1:] {
+		switch l.Kind {
+		case handlog.StreetLine:
+			board = append(board, l.Cards...)
+			strength = Strengths(board)
+			bet = false
+		case handlog.BetLine:
+			verb := l.Verb
+			if verb == handlog.Raise {
+				if bet {
+					verb = reraise
+				}
+				bet = true
+			}
+			r, ok := held[l.Player]
+			if !ok {
+				continue
+			}
+			if verb == handlog.Fold {
+				delete(held, l.Player)
+				continue
+			}
+			for i, s := range strength {
+				if r.At(i) > 0 {
+					r.Scale(i, likelihood(verb, s))
+				}
+			}
+		}
+	}
+	return held
+}
+
+// Strengths returns the strength of each combo on board: the share of the
+// other combos it beats, ties counting half. Combos that use a board card
+// are 0.
+func Strengths(board []poker.Card) []float64 {
+	type ranked struct {
+		combo int
+		rank  int32
+	}
+	var all []ranked
+	hand := make([]poker.Card, 0, 7)
+	for i := 0; i < ranges.NumCombos; i++ {
+		a, b := ranges.ComboCards(i)
+		if contains(board, a) || contains(board, b) {
+			continue
+		}
+		all = append(all, ranked{i, poker.Evaluate(append(append(hand[:0], a, b), board...))})
+	}
+	// Worst first, so that a combo's position counts the hands it beats
+	sort.Slice(all, func(i, j int) bool { return all[i].rank > all[j].rank })
+	s := make([]float64, ranges.NumCombos)
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].rank == all[i].rank {
+			j++
+		}
+		for k := i; k < j; k++ {
+			s[all[k].combo] = (float64(i) + float64(j-i-1)/2) / float64(len(all)-1)
+		}
+		i = j
+	}
+	return s
+}
+
+func contains(cs []poker.Card, c poker.Card) bool {
+	for _, x := range cs {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// ChenPercentile returns the strength of a and b before the flop: the share
+// of combos with a lower Chen score, ties counting half. Unlike
+// cards.PreflopStrength, a score of the hand alone, it is a share of combos
+// like the Strengths of later streets, which ranges are narrowed by.
+func ChenPercentile(a, b poker.Card) float64 {
+	return preflop[ranges.Combo(a, b)]
+}
+
+// preflop is the strength of each combo before the flop, by its Chen score.
+var preflop = make([]float64, ranges.NumCombos)
+
+func init() {
+	scores := make([]float64, ranges.NumCombos)
+	order := make([]int, ranges.NumCombos)
+	for i := range scores {
+		a, b := ranges.ComboCards(i)
+		scores[i] = chen(a, b)
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return scores[order[i]] < scores[order[j]] })
+	for i := 0; i < len(order); {
+		j := i
+		for j < len(order) && scores[order[j]] == scores[order[i]] {
+			j++
+		}
+		for k := i; k < j; k++ {
+			preflop[order[k]] = (float64(i) + float64(j-i-1)/2) / float64(len(order)-1)
+		}
+		i = j
+	}
+}
+
+// chen scores a starting hand by Bill Chen's formula.
+func chen(a, b poker.Card) float64 {
+	hi, lo := cards.Rank(a), cards.Rank(b)
+	if lo > hi {
+		hi, lo = lo, hi
+	}
+	score := []float64{1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5, 5, 6, 7, 8, 10}[hi]
+	if hi == lo {
+		score *= 2
+		if score < 5 {
+			score = 5
+		}
+		return score
+	}
+	if cards.Suit(a) == cards.Suit(b) {
+		score += 2
+	}
+	switch gap := hi - lo - 1; {
+	case gap == 1:
+		score--
+	case gap == 2:
+		score -= 2
+	case gap == 3:
+		score -= 4
+	case gap >= 4:
+		score -= 5
+	}
+	if hi-lo <= 2 && hi < 10 {
+		score++ // connected below the queen
+	}
+	return score
+}
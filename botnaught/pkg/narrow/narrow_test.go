@@ -0,0 +1,90 @@
+package narrow
+
+import (
+	"strings"
+	"testing"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	ranges "go-poker-project/Botnaught/botnaught/pkg/ranges"
+)
+
+func TestPrior(t *testing.T) {
+	early, button := Prior(3, 6), Prior(0, 6)
+	if early.Combos() >= button.Combos() {
+		t.Errorf("early position prior holds %.1f combos, button %.1f: want it tighter", early.Combos(), button.Combos())
+	}
+	for _, r := range []ranges.Range{early, button} {
+		if w := r.Weight(cards.MustParse("As")[0], cards.MustParse("Ah")[0]); w != 1 {
+			t.Errorf("aces weigh %v, want 1", w)
+		}
+		if w := r.Weight(cards.MustParse("7s")[0], cards.MustParse("2h")[0]); w != Outside {
+			t.Errorf("seven-deuce weighs %v, want %v", w, Outside)
+		}
+	}
+}
+
+func TestStrengths(t *testing.T) {
+	s := Strengths(cards.MustParse("Kc7h2s9d4c"))
+	weight := func(hand string) float64 {
+		cs := cards.MustParse(hand)
+		return s[ranges.Combo(cs[0], cs[1])]
+	}
+	if !(weight("7s7d") > weight("AsKd") && weight("AsKd") > weight("KsQd") && weight("KsQd") > weight("8s6d")) {
+		t.Errorf("strengths: set %.3f, top pair %.3f, weaker kicker %.3f, nothing %.3f: want decreasing",
+			weight("7s7d"), weight("AsKd"), weight("KsQd"), weight("8s6d"))
+	}
+	if w := weight("KcQd"); w != 0 {
+		t.Errorf("combo using a board card has strength %v, want 0", w)
+	}
+}
+
+// mean returns the mean strength on board of the hands in r.
+func mean(r *ranges.Range, board []poker.Card) float64 {
+	s := Strengths(board)
+	var sum, total float64
+	for i := 0; i < ranges.NumCombos; i++ {
+		sum += r.At(i) * s[i]
+		total += r.At(i)
+	}
+	return sum / total
+}
+
+func TestHand(t *testing.T) {
+	seats := []string{"btn", "sb", "bb", "them", "mp", "me"}
+	board := cards.MustParse("Kc7h2s9d4c")
+	hand := func(river ...string) *ranges.Range {
+		log := strings.Split(`-- hand 1 button btn
+sb small_blind 1
+bb big_blind 2
+them raise 6
+mp fold
+me call 6
+btn fold
+sb fold
+bb fold
+-- flop Kc 7h 2s
+them check
+me raise 8
+them call 8
+-- turn 9d
+them check
+me check
+-- river 4c`, "\n")
+		held := Hand(append(log, river...), seats, "me", cards.MustParse("KsQd"), board)
+		if len(held) != 1 || held["them"] == nil {
+			t.Fatalf("Hand() = %v, want only them", held)
+		}
+		return held["them"]
+	}
+	checked := hand("them check")
+	led := hand("them raise 20")
+	checkRaised := hand("them check", "me raise 20", "them raise 60")
+	if !(mean(checkRaised, board) > mean(led, board) && mean(led, board) > mean(checked, board)) {
+		t.Errorf("mean strength after a check %.3f, a lead %.3f, a check-raise %.3f: want increasing",
+			mean(checked, board), mean(led, board), mean(checkRaised, board))
+	}
+	if w := checkRaised.Weight(cards.MustParse("Ks")[0], cards.MustParse("Kd")[0]); w != 0 {
+		t.Errorf("a combo holding our cards weighs %v, want 0", w)
+	}
+}
@@ -0,0 +1,168 @@
+// Package opponent counts each opponent's tendencies from the hands in
+// game.Game.HandLog: how often they play and raise before the flop, fold to
+// continuation bets, call bets and raise after the flop.
+package opponent
+
+import (
+	"sync"
+
+	handlog "go-poker-project/Botnaught/botnaught/pkg/handlog"
+)
+
+// Stats are an opponent's counts over the finished hands observed.
+type Stats struct {
+	Hands int `json:"hands"`
+	// VPIP counts hands they put chips in before the flop other than the
+	// blinds, PFR hands they raised before the flop.
+	VPIP int `json:"vpip"`
+	PFR  int `json:"pfr"`
+	// CbetsFaced counts flops where the preflop raiser bet into them, and
+	// CbetFolds those they folded.
+	CbetsFaced int `json:"cbets_faced"`
+	CbetFolds  int `json:"cbet_folds"`
+	// BetsFaced counts their actions after the flop facing a bet, split
+	// into folds, calls and raises.
+	BetsFaced int `json:"bets_faced"`
+	Folds     int `json:"folds"`
+	Calls     int `json:"calls"`
+	Raises    int `json:"raises"`
+	// Bets counts their bets after the flop when nobody had bet.
+	Bets int `json:"bets"`
+}
+
+// FoldToCbet is the share of continuation bets they folded to.
+func (s Stats) FoldToCbet() float64 {
+	return ratio(s.CbetFolds, s.CbetsFaced)
+}
+
+// CallRate is the share of bets after the flop they called.
+func (s Stats) CallRate() float64 {
+	return ratio(s.Calls, s.BetsFaced)
+}
+
+// Aggression is the share of their actions after the flop that bet or raise,
+// out of bets, raises and calls.
+func (s Stats) Aggression() float64 {
+	return ratio(s.Bets+s.Raises, s.Bets+s.Raises+s.Calls)
+}
+
+// PlayRate is the share of hands they played before the flop.
+func (s Stats) PlayRate() float64 {
+	return ratio(s.VPIP, s.Hands)
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// Tracker counts the Stats of everyone seen in the games it observes. The
+// zero value is ready to use.
+type Tracker struct {
+	mu      sync.Mutex
+	seen    handlog.Seen
+	stats   map[string]Stats
+	counted int64
+}
+
+// Observe counts every finished hand of log, from the game gameID, that it
+// hasn't counted before.
+func (t *Tracker) Observe(gameID string, log []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, hand := range t.seen.Finished(gameID, log) {
+		if t.stats == nil {
+			t.stats = map[string]Stats{}
+		}
+		Count(hand, t.stats)
+		t.counted++
+	}
+}
+
+// Stats returns player's stats.
+func (t *Tracker) Stats(player string) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats[player]
+}
+
+// Version returns a number that changes whenever the stats do: the hands
+// counted so far.
+func (t *Tracker) Version() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counted
+}
+
+// Count adds the actions of hand, the parsed lines of one hand, to stats.
+func Count(hand []handlog.Line, stats map[string]Stats) {
+	street := "preflop"
+	dealt := map[string]bool{}
+	vpip, pfr := map[string]bool{}, map[string]bool{}
+	raiser, bet := "", false
+	cbettor := "" // whoever continuation bet this flop
+	facedCbet := map[string]bool{}
+	for _, l := range hand {
+		switch l.Kind {
+		case handlog.StreetLine:
+			street, bet, cbettor = l.Street, false, ""
+			continue
+		case handlog.BetLine:
+		default:
+			continue
+		}
+		dealt[l.Player] = true
+		s := stats[l.Player]
+		if street == "preflop" {
+			switch l.Verb {
+			case handlog.Call:
+				vpip[l.Player] = true
+			case handlog.Raise:
+				vpip[l.Player], pfr[l.Player] = true, true
+				raiser = l.Player
+			}
+			continue
+		}
+		if cbettor != "" && l.Player != cbettor && !facedCbet[l.Player] {
+			facedCbet[l.Player] = true
+			s.CbetsFaced++
+			if l.Verb == handlog.Fold {
+				s.CbetFolds++
+			}
+		}
+		switch {
+		case bet:
+			s.BetsFaced++
+			switch l.Verb {
+			case handlog.Fold:
+				s.Folds++
+			case handlog.Call:
+				s.Calls++
+			case handlog.Raise:
+				s.Raises++
+			}
+		case l.Verb == handlog.Raise:
+			s.Bets++
+			if street == "flop" && l.Player == raiser {
+				cbettor = l.Player
+			}
+		}
+		if l.Verb == handlog.Raise {
+			bet = true
+		}
+		stats[l.Player] = s
+	}
+	for name := range dealt {
+		s := stats[name]
+		s.Hands++
+		if vpip[name] {
+			s.VPIP++
+		}
+		if pfr[name] {
+			s.PFR++
+		}
+		stats[name] = s
+	}
+}
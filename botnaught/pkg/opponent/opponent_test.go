@@ -0,0 +1,68 @@
+package opponent
+
+import (
+	"strings"
+	"testing"
+)
+
+// game is three hands: Vinnie raises and c-bets the first two flops, Guido
+// folds to one and calls the other; the third is still being played.
+var game = strings.Split(`-- hand 1 button Vinnie
+Vinnie small_blind 1
+Guido big_blind 2
+Vinnie raise 6
+Guido call 6
+-- flop Kc 7h 2s
+Guido check
+Vinnie raise 8
+Guido fold
+Vinnie wins 20
+-- hand 2 button Guido
+Guido small_blind 1
+Vinnie big_blind 2
+Guido call 2
+Vinnie raise 6
+Guido call 6
+-- flop Ac 8h 3s
+Vinnie raise 6
+Guido call 6
+-- turn Td
+Vinnie check
+Guido raise 12
+Vinnie fold
+Guido wins 36
+-- hand 3 button Vinnie
+Vinnie small_blind 1
+Guido big_blind 2
+Vinnie raise 6`, "\n")
+
+func TestTracker(t *testing.T) {
+	var tr Tracker
+	tr.Observe("g", game)
+	tr.Observe("g", game) // hands already counted are skipped
+	if v := tr.Version(); v != 2 {
+		t.Errorf("Version() = %d after two hands, want 2", v)
+	}
+
+	guido := tr.Stats("Guido")
+	want := Stats{Hands: 2, VPIP: 2, CbetsFaced: 2, CbetFolds: 1, BetsFaced: 2, Folds: 1, Calls: 1, Bets: 1}
+	if guido != want {
+		t.Errorf("Guido: got %+v, want %+v", guido, want)
+	}
+	if got := guido.FoldToCbet(); got != .5 {
+		t.Errorf("Guido folds to %v of c-bets, want .5", got)
+	}
+	if got := guido.Aggression(); got != .5 {
+		t.Errorf("Guido's aggression is %v, want .5", got)
+	}
+
+	vinnie := tr.Stats("Vinnie")
+	want = Stats{Hands: 2, VPIP: 2, PFR: 2, BetsFaced: 1, Folds: 1, Bets: 2}
+	if vinnie != want {
+		t.Errorf("Vinnie: got %+v, want %+v", vinnie, want)
+	}
+
+	if s := tr.Stats("Nobody"); s != (Stats{}) {
+		t.Errorf("Nobody: got %+v", s)
+	}
+}
@@ -0,0 +1,151 @@
+package ranges
+
+import (
+	"math/rand"
+	"sort"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// HandEquity returns the share of the pot hole wins on board against an
+// opponent holding a hand from r. The river is enumerated exactly; earlier
+// streets play trials random opponent hands and run-outs drawn from rnd.
+// Hands in r that share a card with hole or board are left out, and when
+// none are left HandEquity returns .5.
+func HandEquity(hole, board []poker.Card, r *Range, trials int, rnd *rand.Rand) float64 {
+	live := *r
+	live.Remove(hole, board)
+	if len(board) == 5 {
+		ours := poker.Evaluate(append(append([]poker.Card(nil), hole...), board...))
+		var won, total float64
+		opp := make([]poker.Card, 0, 7)
+		for i, w := range live.w {
+			if w == 0 {
+				continue
+			}
+			a, b := ComboCards(i)
+			won += w * share(ours, poker.Evaluate(append(append(opp[:0], a, b), board...)))
+			total += w
+		}
+		if total == 0 {
+			return .5
+		}
+		return won / total
+	}
+
+	s := newSampler(&live)
+	if s == nil || trials <= 0 {
+		return .5
+	}
+	deck := cards.Without(hole, board)
+	mine := make([]poker.Card, 0, 7)
+	theirs := make([]poker.Card, 0, 7)
+	won := 0.0
+	for t := 0; t < trials; t++ {
+		a, b := s.draw(rnd)
+		runout := deal(deck, 5-len(board), rnd, a, b)
+		mine = append(append(append(mine[:0], hole...), board...), runout...)
+		theirs = append(append(append(theirs[:0], a, b), board...), runout...)
+		won += share(poker.Evaluate(mine), poker.Evaluate(theirs))
+	}
+	return won / float64(trials)
+}
+
+// Equity returns the share of the pot a hand from ours wins on board against
+// a hand from theirs, over trials pairs of hands and run-outs drawn from
+// rnd.
+func Equity(ours, theirs *Range, board []poker.Card, trials int, rnd *rand.Rand) float64 {
+	a, b := *ours, *theirs
+	a.Remove(board)
+	b.Remove(board)
+	sa, sb := newSampler(&a), newSampler(&b)
+	if sa == nil || sb == nil || trials <= 0 {
+		return .5
+	}
+	deck := cards.Without(board)
+	mine := make([]poker.Card, 0, 7)
+	other := make([]poker.Card, 0, 7)
+	won, played := 0.0, 0
+	for t := 0; t < trials; t++ {
+		m1, m2 := sa.draw(rnd)
+		o1, o2 := sb.draw(rnd)
+		if m1 == o1 || m1 == o2 || m2 == o1 || m2 == o2 {
+			continue
+		}
+		runout := deal(deck, 5-len(board), rnd, m1, m2, o1, o2)
+		mine = append(append(append(mine[:0], m1, m2), board...), runout...)
+		other = append(append(append(other[:0], o1, o2), board...), runout...)
+		won += share(poker.Evaluate(mine), poker.Evaluate(other))
+		played++
+	}
+	if played == 0 {
+		return .5
+	}
+	return won / float64(played)
+}
+
+// share is what ours wins of a pot against theirs: 1, a half for a tie or 0.
+func share(ours, theirs int32) float64 {
+	switch {
+	case ours < theirs:
+		return 1
+	case ours == theirs:
+		return .5
+	}
+	return 0
+}
+
+// sampler draws combos in proportion to their weight.
+type sampler struct {
+	combos []int
+	cum    []float64
+}
+
+// newSampler returns a sampler for r, or nil when r is empty.
+func newSampler(r *Range) *sampler {
+	s := &sampler{}
+	total := 0.0
+	for i, w := range r.w {
+		if w > 0 {
+			total += w
+			s.combos = append(s.combos, i)
+			s.cum = append(s.cum, total)
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	return s
+}
+
+func (s *sampler) draw(rnd *rand.Rand) (poker.Card, poker.Card) {
+	x := rnd.Float64() * s.cum[len(s.cum)-1]
+	i := sort.SearchFloat64s(s.cum, x)
+	if i == len(s.cum) {
+		i--
+	}
+	return ComboCards(s.combos[i])
+}
+
+// deal draws n cards from deck other than the dead ones.
+func deal(deck []poker.Card, n int, rnd *rand.Rand, dead ...poker.Card) []poker.Card {
+	out := make([]poker.Card, 0, n)
+	for len(out) < n {
+		c := deck[rnd.Intn(len(deck))]
+		if contains(dead, c) || contains(out, c) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func contains(cs []poker.Card, c poker.Card) bool {
+	for _, x := range cs {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
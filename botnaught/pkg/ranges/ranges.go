@@ -0,0 +1,268 @@
+// Package ranges holds weighted hand ranges: a weight for each of the 1326
+// two-card combinations an opponent may hold, read from the usual notation
+// such as "22+, A2s+, KTo+", and the equity of hands and ranges against
+// them.
+package ranges
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// NumCombos is how many two-card hands there are.
+const NumCombos = 52 * 51 / 2
+
+var (
+	comboIndex [52][52]int
+	comboCards [NumCombos][2]int
+)
+
+func init() {
+	n := 0
+	for i := 0; i < 52; i++ {
+		for j := i + 1; j < 52; j++ {
+			comboIndex[i][j], comboIndex[j][i] = n, n
+			comboCards[n] = [2]int{i, j}
+			n++
+		}
+	}
+}
+
+// Combo returns the index, below NumCombos, of the hand a and b.
+func Combo(a, b poker.Card) int {
+	return comboIndex[cards.Index(a)][cards.Index(b)]
+}
+
+// ComboCards returns the cards of combo i.
+func ComboCards(i int) (poker.Card, poker.Card) {
+	return cards.FromIndex(comboCards[i][0]), cards.FromIndex(comboCards[i][1])
+}
+
+// Range is the weight of every combo, 0 for hands not in it and 1 for hands
+// held every time. The zero Range is empty.
+type Range struct {
+	w [NumCombos]float64
+}
+
+// Full returns the range of every hand.
+func Full() Range {
+	var r Range
+	for i := range r.w {
+		r.w[i] = 1
+	}
+	return r
+}
+
+// Weight returns the weight of the hand a and b, so that a *Range is a
+// handeval.Weighting.
+func (r *Range) Weight(a, b poker.Card) float64 {
+	if a == b {
+		return 0
+	}
+	return r.w[Combo(a, b)]
+}
+
+// Set sets the weight of the hand a and b.
+func (r *Range) Set(a, b poker.Card, w float64) {
+	r.w[Combo(a, b)] = w
+}
+
+// At returns the weight of combo i.
+func (r *Range) At(i int) float64 {
+	return r.w[i]
+}
+
+// SetAt sets the weight of combo i.
+func (r *Range) SetAt(i int, w float64) {
+	r.w[i] = w
+}
+
+// Scale multiplies the weight of combo i by f.
+func (r *Range) Scale(i int, f float64) {
+	r.w[i] *= f
+}
+
+// Remove drops every hand holding one of the dead cards.
+func (r *Range) Remove(dead ...[]poker.Card) {
+	for _, cs := range dead {
+		for _, c := range cs {
+			d := cards.Index(c)
+			for o := 0; o < 52; o++ {
+				if o != d {
+					r.w[comboIndex[d][o]] = 0
+				}
+			}
+		}
+	}
+}
+
+// Combos returns the total weight of the range, the number of hands it
+// holds when every weight is 0 or 1.
+func (r *Range) Combos() float64 {
+	total := 0.0
+	for _, w := range r.w {
+		total += w
+	}
+	return total
+}
+
+// Parse reads a range written as comma-separated parts, each optionally
+// followed by ":weight":
+//
+//	AA, 77       pocket pairs
+//	TT+, 22-55   pairs from TT up, pairs from 22 to 55
+//	AKs, AKo, AK suited, offsuit or both
+//	A2s+, KTo+   the second card up to one below the first
+//	A2s-A5s      the second card from 2 to 5
+//	AhKh         one combo
+//
+// Later parts overwrite the weights of earlier ones.
+func Parse(s string) (Range, error) {
+	var r Range
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		w := 1.0
+		if colon := strings.Index(part, ":"); colon >= 0 {
+			var err error
+			if w, err = strconv.ParseFloat(part[colon+1:], 64); err != nil || w < 0 {
+				return Range{}, fmt.Errorf("range part %q: bad weight", part)
+			}
+			part = part[:colon]
+		}
+		if err := r.setPart(part, w); err != nil {
+			return Range{}, err
+		}
+	}
+	return r, nil
+}
+
+// MustParse is Parse for ranges known to be valid.
+func MustParse(s string) Range {
+	r, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *Range) setPart(part string, w float64) error {
+	bad := fmt.Errorf("range part %q: want e.g. TT+, A2s+, KTo, A2s-A5s or AhKh", part)
+	if len(part) == 4 && strings.IndexByte(cards.Suits, part[1]) >= 0 {
+		cs, err := cards.Parse(part)
+		if err != nil || cs[0] == cs[1] {
+			return bad
+		}
+		r.Set(cs[0], cs[1], w)
+		return nil
+	}
+
+	hi, lo, kind, ok := hand(strings.TrimSuffix(part, "+"))
+	if !ok {
+		if dash := strings.Index(part, "-"); dash > 0 {
+			hi1, lo1, kind1, ok1 := hand(part[:dash])
+			hi2, lo2, kind2, ok2 := hand(part[dash+1:])
+			if !ok1 || !ok2 || kind1 != kind2 {
+				return bad
+			}
+			if hi1 == lo1 && hi2 == lo2 {
+				for p := min(hi1, hi2); p <= max(hi1, hi2); p++ {
+					r.setHand(p, p, kind1, w)
+				}
+				return nil
+			}
+			if hi1 != hi2 || hi1 == lo1 || hi2 == lo2 {
+				return bad
+			}
+			for l := min(lo1, lo2); l <= max(lo1, lo2); l++ {
+				r.setHand(hi1, l, kind1, w)
+			}
+			return nil
+		}
+		return bad
+	}
+	switch {
+	case !strings.HasSuffix(part, "+"):
+		r.setHand(hi, lo, kind, w)
+	case hi == lo:
+		for p := hi; p < 13; p++ {
+			r.setHand(p, p, kind, w)
+		}
+	default:
+		for l := lo; l < hi; l++ {
+			r.setHand(hi, l, kind, w)
+		}
+	}
+	return nil
+}
+
+// Kinds of non-pair hands.
+const (
+	anySuits = iota
+	suited
+	offsuit
+)
+
+// hand reads "AK", "AKs", "AKo" or "TT" into its ranks, high first.
+func hand(s string) (hi, lo, kind int, ok bool) {
+	if len(s) < 2 || len(s) > 3 {
+		return
+	}
+	hi, lo = strings.IndexByte(cards.Ranks, s[0]), strings.IndexByte(cards.Ranks, s[1])
+	if hi < 0 || lo < 0 {
+		return
+	}
+	if lo > hi {
+		hi, lo = lo, hi
+	}
+	if len(s) == 3 {
+		switch s[2] {
+		case 's':
+			kind = suited
+		case 'o':
+			kind = offsuit
+		default:
+			return
+		}
+		if hi == lo {
+			return
+		}
+	}
+	return hi, lo, kind, true
+}
+
+// setHand weighs every combo of ranks hi and lo of the kind.
+func (r *Range) setHand(hi, lo, kind int, w float64) {
+	for s1 := 0; s1 < 4; s1++ {
+		for s2 := 0; s2 < 4; s2++ {
+			a, b := hi*4+s1, lo*4+s2
+			switch {
+			case a == b, hi == lo && s1 > s2:
+				continue
+			case kind == suited && s1 != s2, kind == offsuit && s1 == s2:
+				continue
+			}
+			r.w[comboIndex[a][b]] = w
+		}
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,80 @@
+package ranges
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec   string
+		combos float64
+	}{
+		{"AA", 6},
+		{"AKs", 4},
+		{"AKo", 12},
+		{"AK", 16},
+		{"22+", 78},
+		{"22+, A2s+, KTo+", 78 + 48 + 36},
+		{"22-44", 18},
+		{"A2s-A5s", 16},
+		{"AhKh", 1},
+		{"AA:0.5, KK", 3 + 6},
+		{"AK, AKs:0", 12},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := r.Combos(); got != tt.combos {
+			t.Errorf("Parse(%q) has %v combos, want %v", tt.spec, got, tt.combos)
+		}
+	}
+	for _, spec := range []string{"AAs", "ZZ", "AKs-KQs", "AK:x", "AhAh", "22-AKs"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+	if full := Full(); full.Combos() != NumCombos {
+		t.Errorf("Full has %v combos, want %d", full.Combos(), NumCombos)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	r := MustParse("AA, AKs")
+	r.Remove(cards.MustParse("Ah"))
+	if got := r.Combos(); got != 3+3 {
+		t.Errorf("AA, AKs without Ah has %v combos, want 6", got)
+	}
+	if w := r.Weight(cards.MustParse("As")[0], cards.MustParse("Ks")[0]); w != 1 {
+		t.Errorf("AsKs weighs %v, want 1", w)
+	}
+}
+
+func TestEquity(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	aces, kings := MustParse("AA"), MustParse("KK")
+	if e := Equity(&aces, &kings, nil, 20000, rnd); math.Abs(e-.82) > .02 {
+		t.Errorf("AA against KK = %.3f, want about .82", e)
+	}
+	if e := HandEquity(cards.MustParse("AsAd"), nil, &kings, 20000, rnd); math.Abs(e-.82) > .02 {
+		t.Errorf("AsAd against KK = %.3f, want about .82", e)
+	}
+
+	// On the river a pair of kings beats every pair below and loses to aces
+	board := cards.MustParse("2c7d9hJsQc")
+	r := MustParse("AA, 33")
+	if e := HandEquity(cards.MustParse("KsKd"), board, &r, 0, rnd); e != .5 {
+		t.Errorf("KsKd against AA, 33 on %s = %.3f, want .5", cards.String(board), e)
+	}
+	empty := MustParse("AA")
+	if e := HandEquity(cards.MustParse("AsAd"), cards.MustParse("AhAc2d"), &empty, 100, rnd); e != .5 {
+		t.Errorf("against an emptied range = %.3f, want .5", e)
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	poker "github.com/chehsunliu/poker"
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	sizing "go-poker-project/Botnaught/botnaught/pkg/sizing"
+)
+
+// The baseline strategies are simple, predictable opponents for tests and
+// arenas. Each takes its own parameters, listed with their defaults in
+// baselineDefaults; they ignore the Bet parameters.
+func init() {
+	strategies["call"] = newCallStrategy
+	strategies["raise"] = newRaiseStrategy
+	strategies["random"] = newRandomStrategy
+	strategies["tight-passive"] = newTightPassiveStrategy
+	strategies["loose-aggressive"] = newLooseAggressiveStrategy
+	strategies["equity"] = newEquityStrategy
+}
+
+var baselineDefaults = map[string]map[string]float64{
+	"call": {},
+	// size is the raise as a fraction of the pot after calling.
+	"raise": {"size": 1},
+	// seed 0 seeds from the clock.
+	"random": {"seed": 0, "fold": 1.0 / 3, "raise": 1.0 / 3, "size": 1},
+	// Hands stronger than call play on, never raising.
+	"tight-passive":    {"call": 0.6},
+	"loose-aggressive": {"call": 0.15, "raise": 0.35, "size": 0.75},
+	// Calls when equity beats the pot odds by margin and raises with
+	// equity above raise, estimated from samples random deals.
+	"equity": {"margin": 0, "raise": 0.65, "size": 0.75, "samples": 400, "seed": 0},
+}
+
+// baselineParams returns the defaults of strategy overridden by params.
+func baselineParams(strategy string, params map[string]float64) (map[string]float64, error) {
+	merged := map[string]float64{}
+	for name, value := range baselineDefaults[strategy] {
+		merged[name] = value
+	}
+	for name, value := range params {
+		if _, ok := merged[name]; !ok {
+			var known []string
+			for k := range merged {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("strategy %s has no parameter %q (has: %s)", strategy, name, strings.Join(known, ", "))
+		}
+		merged[name] = value
+	}
+	return merged, nil
+}
+
+// newRand returns a generator seeded by seed, or by the clock when seed is 0.
+func newRand(seed float64) *rand.Rand {
+	if seed == 0 {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(rand.NewSource(int64(seed)))
+}
+
+// baselineHealth is the health of a strategy that needs nothing but the
+// evaluator.
+func baselineHealth() HealthStatus {
+	status := newHealthStatus()
+	status.EvaluatorOK = evaluatorSelfTest()
+	if !status.EvaluatorOK {
+		status.Problems = append(status.Problems, "evaluator self-test failed")
+	}
+	status.Ready = status.EvaluatorOK
+	return status
+}
+
+// seat is what the baseline strategies read off a game.Game.
+type seat struct {
+	me         game.PokerPlayer
+	board      []poker.Card
+	toCall     int
+	pot        int
+	currentBet int
+	minRaise   int
+	canRaise   bool
+	opponents  int
+	passive    game.Action
+}
+
+func newSeat(g game.Game) seat {
+	s := seat{board: g.CommunityCards, pot: g.PotSize, currentBet: g.CurrentBet, passive: game.Action{SelectedAction: "check"}}
+	for _, p := range g.PokerPlayers {
+		switch {
+		case len(p.HoleCards) > 0:
+			s.me = p
+		case p.IsPlayingHand:
+			s.opponents++
+		}
+	}
+	s.toCall = g.CurrentBet - s.me.ChipsCommittedThisAction
+	s.minRaise = sizing.Spot{CurrentBet: g.CurrentBet, LastRaise: lastRaise(g.HandLog), BigBlind: g.BigBlind}.MinRaise()
+	for _, action := range g.AvailableActions {
+		switch action {
+		case "call":
+			s.passive.SelectedAction = "call"
+		case "raise":
+			s.canRaise = true
+		}
+	}
+	return s
+}
+
+// fold folds, or checks when that is free.
+func (s seat) fold() game.Action {
+	if s.toCall <= 0 {
+		return s.passive
+	}
+	return game.Action{SelectedAction: "fold"}
+}
+
+// raise raises by size times the pot after calling, at least the minimum
+// raise and at most all in. It calls or checks when raising isn't allowed or
+// all in doesn't reach past the current bet.
+func (s seat) raise(size float64) game.Action {
+	if !s.canRaise {
+		return s.passive
+	}
+	by := int(math.Round(size * float64(s.pot+s.toCall)))
+	to := s.currentBet + by
+	if to < s.minRaise {
+		to = s.minRaise
+	}
+	if max := s.me.ChipsCommittedThisAction + s.me.Chips; to > max {
+		to = max
+	}
+	if to <= s.currentBet {
+		return s.passive
+	}
+	return game.Action{SelectedAction: "raise", Value: to}
+}
+
+// strength scores a hand from 0 to 1: before the flop by
+// cards.PreflopStrength, after it by the share of five-card ranks it beats.
+func strength(hole, board []poker.Card) float64 {
+	if len(hole) < 2 {
+		return 0
+	}
+	if len(board) < 3 {
+		return cards.PreflopStrength(hole)
+	}
+	rank := poker.Evaluate(append(append([]poker.Card(nil), hole...), board...))
+	return 1 - float64(rank)/7462
+}
+
+// callService calls or checks every time.
+type callService struct{}
+
+func newCallStrategy(name string, base BetParams, params map[string]float64) (BotnaughtService, error) {
+	if _, err := baselineParams("call", params); err != nil {
+		return nil, err
+	}
+	return callService{}, nil
+}
+
+func (callService) Health(ctx context.Context) (HealthStatus, error) {
+	return baselineHealth(), nil
+}
+
+func (callService) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	return newSeat(g).passive, nil
+}
+
+// raiseService raises whenever it may and calls otherwise.
+type raiseService struct {
+	size float64
+}
+
+func newRaiseStrategy(name string, base BetParams, params map[string]float64) (BotnaughtService, error) {
+	p, err := baselineParams("raise", params)
+	if err != nil {
+		return nil, err
+	}
+	return raiseService{size: p["size"]}, nil
+}
+
+func (r raiseService) Health(ctx context.Context) (HealthStatus, error) {
+	return baselineHealth(), nil
+}
+
+func (r raiseService) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	return newSeat(g).raise(r.size), nil
+}
+
+// randomService picks a legal action at random: fold and raise with their
+// probabilities, call or check otherwise. It never folds when it can check.
+type randomService struct {
+	mu                sync.Mutex
+	rand              *rand.Rand
+	fold, raise, size float64
+}
+
+func newRandomStrategy(name string, base BetParams, params map[string]float64) (BotnaughtService, error) {
+	p, err := baselineParams("random", params)
+	if err != nil {
+		return nil, err
+	}
+	if p["fold"] < 0 || p["raise"] < 0 || p["fold"]+p["raise"] > 1 {
+		return nil, fmt.Errorf("random: fold and raise must be probabilities summing to at most 1")
+	}
+	return &randomService{rand: newRand(p["seed"]), fold: p["fold"], raise: p["raise"], size: p["size"]}, nil
+}
+
+func (r *randomService) Health(ctx context.Context) (HealthStatus, error) {
+	return baselineHealth(), nil
+}
+
+func (r *randomService) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	r.mu.Lock()
+	x := r.rand.Float64()
+	r.mu.Unlock()
+	s := newSeat(g)
+	switch {
+	case x < r.fold:
+		return s.fold(), nil
+	case x < r.fold+r.raise:
+		return s.raise(r.size), nil
+	}
+	return s.passive, nil
+}
+
+// thresholdService plays by strength: it folds below call, raises from
+// raise up and calls in between. A raise of more than 1 is never reached.
+type thresholdService struct {
+	call, raise, size float64
+}
+
+func newTightPassiveStrategy(name string, base BetParams, params map[string]float64) (BotnaughtService, error) {
+	p, err := baselineParams("tight-passive", params)
+	if err != nil {
+		return nil, err
+	}
+	return thresholdService{call: p["call"], raise: 2}, nil
+}
+
+func newLooseAggressiveStrategy(name string, base BetParams, params map[string]float64) (BotnaughtService, error) {
+	p, err := baselineParams("loose-aggressive", params)
+	if err != nil {
+		return nil, err
+	}
+	return thresholdService{call: p["call"], raise: p["raise"], size: p["size"]}, nil
+}
+
+func (t thresholdService) Health(ctx context.Context) (HealthStatus, error) {
+	return baselineHealth(), nil
+}
+
+func (t thresholdService) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	s := newSeat(g)
+	switch st := strength(s.me.HoleCards, s.board); {
+	case st >= t.raise:
+		return s.raise(t.size), nil
+	case st >= t.call:
+		return s.passive, nil
+	}
+	return s.fold(), nil
+}
+
+// equityService estimates its equity against random hands for every
+// opponent still in and compares it with the pot odds.
+type equityService struct {
+	mu                  sync.Mutex
+	rand                *rand.Rand
+	margin, raise, size float64
+	samples             int
+}
+
+func newEquityStrategy(name string, base BetParams, params map[string]float64) (BotnaughtService, error) {
+	p, err := baselineParams("equity", params)
+	if err != nil {
+		return nil, err
+	}
+	if p["samples"] < 1 {
+		return nil, fmt.Errorf("equity: samples must be at least 1")
+	}
+	return &equityService{rand: newRand(p["seed"]), margin: p["margin"], raise: p["raise"], size: p["size"], samples: int(p["samples"])}, nil
+}
+
+func (e *equityService) Health(ctx context.Context) (HealthStatus, error) {
+	return baselineHealth(), nil
+}
+
+func (e *equityService) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	s := newSeat(g)
+	if len(s.me.HoleCards) < 2 {
+		return s.fold(), nil
+	}
+	eq := e.equity(s.me.HoleCards, s.board, s.opponents)
+	odds := 0.0
+	if s.toCall > 0 {
+		odds = float64(s.toCall) / float64(s.pot+s.toCall)
+	}
+	switch {
+	case eq >= e.raise:
+		return s.raise(e.size), nil
+	case eq >= odds+e.margin:
+		return s.passive, nil
+	}
+	return s.fold(), nil
+}
+
+// equity is the share of sampled deals, opponents' hands and the rest of the
+// board, that hole wins, ties split.
+func (e *equityService) equity(hole, board []poker.Card, opponents int) float64 {
+	if opponents < 1 {
+		return 1
+	}
+	deck := cards.Without(hole, board)
+	need := opponents*2 + 5 - len(board)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	won := 0.0
+	for i := 0; i < e.samples; i++ {
+		for j := 0; j < need; j++ {
+			k := j + e.rand.Intn(len(deck)-j)
+			deck[j], deck[k] = deck[k], deck[j]
+		}
+		full := append(append([]poker.Card(nil), board...), deck[opponents*2:need]...)
+		mine := poker.Evaluate(append(append([]poker.Card(nil), hole...), full...))
+		ties := 1
+		for o := 0; o < opponents && ties > 0; o++ {
+			theirs := poker.Evaluate(append(append([]poker.Card(nil), deck[o*2:o*2+2]...), full...))
+			switch {
+			case theirs < mine:
+				ties = 0
+			case theirs == mine:
+				ties++
+			}
+		}
+		if ties > 0 {
+			won += 1 / float64(ties)
+		}
+	}
+	return won / float64(e.samples)
+}
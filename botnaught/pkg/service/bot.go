@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	showdown "go-poker-project/Botnaught/botnaught/pkg/showdown"
+)
+
+// BotDefinition describes one bot hosted by the process: the name it
+// registers with the game server under, the strategy it plays and that
+// strategy's parameters.
+type BotDefinition struct {
+	Name     string             `json:"name" yaml:"name"`
+	Strategy string             `json:"strategy" yaml:"strategy"`
+	Params   map[string]float64 `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// ParseBotDefinition reads a bot written as name=strategy[,param=value...],
+// e.g. "Loose=basic,all_in_strength=0.6".
+func ParseBotDefinition(spec string) (BotDefinition, error) {
+	eq := strings.Index(spec, "=")
+	if eq < 0 {
+		return BotDefinition{}, fmt.Errorf("bot %q: want name=strategy[,param=value...]", spec)
+	}
+	fields := strings.Split(spec[eq+1:], ",")
+	def := BotDefinition{Name: spec[:eq], Strategy: fields[0]}
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return BotDefinition{}, fmt.Errorf("bot %q: parameter %q is not param=value", spec, field)
+		}
+		value, err := strconv.ParseFloat(kv[1], 64)
+		if err != nil {
+			return BotDefinition{}, fmt.Errorf("bot %q: parameter %s: %v", spec, kv[0], err)
+		}
+		if def.Params == nil {
+			def.Params = map[string]float64{}
+		}
+		def.Params[kv[0]] = value
+	}
+	return def, nil
+}
+
+// StrategyFactory builds the BotnaughtService for the bot called name. base
+// holds the process-wide Bet parameters, which params may override for
+// strategies that play Bet.
+type StrategyFactory func(name string, base BetParams, params map[string]float64) (BotnaughtService, error)
+
+var strategies = map[string]StrategyFactory{
+	"basic": newBasicStrategy,
+}
+
+// Validate reports whether the definitions can be hosted side by side: every
+// bot needs a name that is safe to use as a URL path segment and unique even
+// ignoring case, since it names the bot's files, and a known strategy.
+func Validate(defs []BotDefinition) error {
+	seen := map[string]string{} // lower-cased name to the name
+	for _, def := range defs {
+		if def.Name == "" {
+			return fmt.Errorf("bot with strategy %q has no name", def.Strategy)
+		}
+		if url.PathEscape(def.Name) != def.Name {
+			return fmt.Errorf("bot name %q is not a valid path segment", def.Name)
+		}
+		if other, ok := seen[strings.ToLower(def.Name)]; ok {
+			if other == def.Name {
+				return fmt.Errorf("bot name %q is used more than once", def.Name)
+			}
+			return fmt.Errorf("bot names %q and %q differ only by case", other, def.Name)
+		}
+		seen[strings.ToLower(def.Name)] = def.Name
+		if _, ok := strategies[def.Strategy]; !ok {
+			return fmt.Errorf("bot %q: unknown strategy %q", def.Name, def.Strategy)
+		}
+	}
+	return nil
+}
+
+// Tunable is implemented by strategies whose Bet parameters can be changed
+// while they are playing.
+type Tunable interface {
+	ParamStore() *ParamStore
+}
+
+// DecisionLogger is implemented by strategies that keep a decision log.
+type DecisionLogger interface {
+	DecisionLog() *DecisionLog
+}
+
+// DecisionCacher is implemented by strategies that cache their decisions.
+type DecisionCacher interface {
+	DecisionCache() *DecisionCache
+}
+
+// ShowdownCollector is implemented by strategies that record the hands
+// opponents show down.
+type ShowdownCollector interface {
+	Showdowns() *showdown.Collector
+}
+
+// Seeder is implemented by strategies that draw a seed for each decision.
+type Seeder interface {
+	Seeds() *Seeds
+}
+
+// Bot is a bot built from a BotDefinition: its service, with middleware, and
+// the runtime controls of the strategy underneath.
+type Bot struct {
+	Definition BotDefinition
+	Service    BotnaughtService
+	Games      *GameTracker
+	Passive    *PassiveSwitch
+	// Params is nil unless the strategy is Tunable.
+	Params *ParamStore
+	// Log is nil unless the strategy is a DecisionLogger.
+	Log *DecisionLog
+	// Policy is nil unless the strategy is a PolicyPlayer.
+	Policy *Policy
+	// Cache is nil unless the strategy is a DecisionCacher.
+	Cache *DecisionCache
+	// Showdowns is nil unless the strategy is a ShowdownCollector.
+	Showdowns *showdown.Collector
+	// Seeds is nil unless the strategy is a Seeder.
+	Seeds *Seeds
+}
+
+// NewBot builds the bot described by def with all of the expected
+// middleware wired in.
+func NewBot(def BotDefinition, base BetParams, middleware []Middleware) (*Bot, error) {
+	factory, ok := strategies[def.Strategy]
+	if !ok {
+		return nil, fmt.Errorf("bot %q: unknown strategy %q", def.Name, def.Strategy)
+	}
+	svc, err := factory(def.Name, base, def.Params)
+	if err != nil {
+		return nil, fmt.Errorf("bot %q: %v", def.Name, err)
+	}
+	bot := &Bot{Definition: def, Games: &GameTracker{}, Passive: &PassiveSwitch{}}
+	if t, ok := svc.(Tunable); ok {
+		bot.Params = t.ParamStore()
+	}
+	if l, ok := svc.(DecisionLogger); ok {
+		bot.Log = l.DecisionLog()
+	}
+	if pp, ok := svc.(PolicyPlayer); ok {
+		bot.Policy = pp.Policy()
+	}
+	if c, ok := svc.(DecisionCacher); ok {
+		bot.Cache = c.DecisionCache()
+	}
+	if sc, ok := svc.(ShowdownCollector); ok {
+		bot.Showdowns = sc.Showdowns()
+	}
+	if sd, ok := svc.(Seeder); ok {
+		bot.Seeds = sd.Seeds()
+	}
+	svc = PassiveMiddleware(bot.Passive)(svc)
+	svc = TrackingMiddleware(bot.Games)(svc)
+	for _, m := range middleware {
+		svc = m(svc)
+	}
+	bot.Service = svc
+	return bot, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	metrics "github.com/go-kit/kit/metrics"
+	handlog "go-poker-project/Botnaught/botnaught/pkg/handlog"
+	iso "go-poker-project/Botnaught/botnaught/pkg/iso"
+)
+
+// DecisionCache remembers the actions a strategy chose, keyed by
+// DecisionKey, so that a retried /action is answered at once with the same
+// action. Entries expire after a TTL
+// and the least recently used are dropped beyond the size limit. The zero
+// value is disabled; Configure turns it on.
+type DecisionCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	lookups metrics.Counter
+	order   *list.List
+	items   map[uint64]*list.Element
+	hits    int64
+	misses  int64
+	// now is time.Now, replaced in tests.
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	key     uint64
+	action  game.Action
+	expires time.Time
+}
+
+// CacheStats counts a cache's lookups.
+type CacheStats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+// Configure empties the cache and sets its limits: at most size entries,
+// each kept for ttl. A size of zero disables it. lookups, when not nil, is
+// added to on every lookup with "result" labelled "hit" or "miss".
+func (c *DecisionCache) Configure(size int, ttl time.Duration, lookups metrics.Counter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size, c.ttl, c.lookups = size, ttl, lookups
+	c.order = list.New()
+	c.items = map[uint64]*list.Element{}
+	if c.now == nil {
+		c.now = time.Now
+	}
+}
+
+// Get returns the action cached for key.
+func (c *DecisionCache) Get(key uint64) (game.Action, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return game.Action{}, false
+	}
+	el, ok := c.items[key]
+	if ok && c.now().After(el.Value.(*cacheEntry).expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		ok = false
+	}
+	result := "miss"
+	if ok {
+		c.order.MoveToFront(el)
+		c.hits++
+		result = "hit"
+	} else {
+		c.misses++
+	}
+	if c.lookups != nil {
+		c.lookups.With("result", result).Add(1)
+	}
+	if !ok {
+		return game.Action{}, false
+	}
+	return el.Value.(*cacheEntry).action, true
+}
+
+// Put caches action for key, dropping the least recently used entry when
+// the cache is full.
+func (c *DecisionCache) Put(key uint64, action game.Action) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		el.Value = &cacheEntry{key: key, action: action, expires: expires}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, action: action, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Stats returns the cache's size and lookup counts.
+func (c *DecisionCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := CacheStats{Hits: c.hits, Misses: c.misses}
+	if c.order != nil {
+		s.Entries = c.order.Len()
+	}
+	return s
+}
+
+// DecisionKey hashes everything in g a decision can depend on: the game and
+// hand, the hole cards and board up to suit isomorphism, the chips of every
+// player, the bets, the actions available and the betting so far this hand,
+// along with versions: of the parameters deciding and of the opponent stats
+// they are adjusted by. Only a retry of the same decision shares a key, so
+// that a mixed strategy samples afresh each time a spot comes up again.
+func DecisionKey(g game.Game, versions ...int64) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	putInt := func(v int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	putString := func(s string) {
+		putInt(int64(len(s)))
+		h.Write([]byte(s))
+	}
+
+	for _, v := range versions {
+		putInt(v)
+	}
+	current := handlog.Current(g.HandLog)
+	putString(g.GameID)
+	if len(current) > 0 {
+		putInt(int64(current[0].Number))
+	}
+	for _, p := range g.PokerPlayers {
+		if len(p.HoleCards) > 0 {
+			putInt(int64(iso.Hash(p.HoleCards, g.CommunityCards)))
+		}
+	}
+	for _, p := range g.PokerPlayers {
+		putString(p.Name)
+		putInt(int64(p.Chips))
+		putInt(int64(p.ChipsCommittedThisAction))
+		if p.IsPlayingHand {
+			putInt(1)
+		} else {
+			putInt(0)
+		}
+	}
+	for _, v := range []int{g.PotSize, g.CurrentBet, g.SmallBlind, g.BigBlind, g.StartingStack} {
+		putInt(int64(v))
+	}
+	for _, a := range g.AvailableActions {
+		putString(a)
+	}
+	for _, l := range current {
+		if l.Kind == handlog.BetLine || l.Kind == handlog.StreetLine {
+			// Street lines count, not the cards they dealt.
+			putString(l.Player + " " + l.Verb)
+			putInt(int64(l.Amount))
+		}
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// DecisionLog is the file a bot appends its reasoning to, one decision at a
+// time. The zero value logs to botnaught-log.log.
+type DecisionLog struct {
+	mu      sync.Mutex
+	path    string
+	discard bool
+}
+
+// Discard throws every later decision away instead of writing it, for bots
+// playing many hands in-process.
+func (l *DecisionLog) Discard() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.discard = true
+}
+
+// Path returns the file decisions are appended to.
+func (l *DecisionLog) Path() string {
+	if l.path == "" {
+		return "botnaught-log.log"
+	}
+	return l.path
+}
+
+// Open opens the log for appending, creating it if it was rotated away.
+func (l *DecisionLog) Open() (io.WriteCloser, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.discard {
+		return nopCloser{ioutil.Discard}, nil
+	}
+	return os.OpenFile(l.Path(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// Writable reports whether the log can be opened for appending.
+func (l *DecisionLog) Writable() bool {
+	f, err := l.Open()
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// Rotate moves the current log aside under a timestamped name, which it
+// returns; the next decision starts a fresh file.
+func (l *DecisionLog) Rotate() (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.discard {
+		return "", errors.New("decision log is discarded")
+	}
+	rotated := l.Path() + "." + time.Now().UTC().Format("20060102-150405")
+	return rotated, os.Rename(l.Path(), rotated)
+}
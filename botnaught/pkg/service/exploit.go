@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+
+	opponent "go-poker-project/Botnaught/botnaught/pkg/opponent"
+)
+
+// Population tendencies the exploit rules measure an opponent against.
+const (
+	typicalFoldToCbet = .45
+	typicalCallRate   = .4
+	typicalAggression = .3
+)
+
+// exploitSample is the sample size at which an opponent's tendency counts
+// for half of what it would with endless hands; exploitMinSample is the
+// fewest spots a rule looks at.
+const (
+	exploitSample    = 30
+	exploitMinSample = 5
+)
+
+// confidence is how far to trust a tendency seen in n spots.
+func confidence(n int) float64 {
+	if n < exploitMinSample {
+		return 0
+	}
+	return float64(n) / float64(n+exploitSample)
+}
+
+// Exploit returns params adjusted to the opponent's tendencies, with a line
+// saying why for each change. Each shift grows with how far the opponent
+// is from the typical player and with the sample behind it, is scaled by
+// params.Exploit and leaves every parameter within its range.
+//
+//   - Opponents who fold to continuation bets get continuation bets on
+//     wet boards with weaker hands, and semi-bluffs with fewer outs; those
+//     who never fold get fewer of both.
+//   - Calling stations are value bet thinner: lower aggression thresholds.
+//   - A raise from a passive opponent means more: we call less.
+func Exploit(params BetParams, s opponent.Stats, situation Situation) (BetParams, []string) {
+	var why []string
+	scale := params.Exploit
+	if scale == 0 {
+		return params, nil
+	}
+	if c := confidence(s.CbetsFaced); c > 0 {
+		shift := (s.FoldToCbet() - typicalFoldToCbet) * c * scale
+		params.CbetWetStrength -= shift
+		params.SemiBluffOuts -= shift * 10
+		why = append(why, fmt.Sprintf("folds to %.0f%% of %d c-bets: c-bet strength %+.2f", 100*s.FoldToCbet(), s.CbetsFaced, -shift))
+	}
+	if c := confidence(s.BetsFaced); c > 0 && s.CallRate() > typicalCallRate {
+		shift := (s.CallRate() - typicalCallRate) * c * scale / 2
+		params.FlopAggression -= shift
+		params.TurnAggression -= shift
+		params.RiverAggression -= shift
+		why = append(why, fmt.Sprintf("calls %.0f%% of %d bets: value bet thresholds %+.2f", 100*s.CallRate(), s.BetsFaced, -shift))
+	}
+	if c := confidence(s.Bets + s.Raises + s.Calls); c > 0 && situation.OpponentRaised && s.Aggression() < typicalAggression {
+		shift := (typicalAggression - s.Aggression()) / typicalAggression * c * scale
+		params.RangeFold += shift / 5
+		params.CallRankCutoff *= 1 - shift/2
+		why = append(why, fmt.Sprintf("passive, %.0f%% aggressive, and raising: calling %.0f%% less", 100*s.Aggression(), 50*shift))
+	}
+	for _, spec := range BetParamSpecs {
+		v := spec.Field(&params)
+		if *v < spec.Min {
+			*v = spec.Min
+		}
+		if *v > spec.Max {
+			*v = spec.Max
+		}
+	}
+	return params, why
+}
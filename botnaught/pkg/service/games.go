@@ -0,0 +1,98 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+)
+
+// activeGameTTL is how long a game counts as active after its last /action.
+const activeGameTTL = 10 * time.Minute
+
+// GameTracker remembers the games a bot has been asked to act in. The zero
+// value is ready to use.
+type GameTracker struct {
+	mu    sync.Mutex
+	games map[string]trackedGame
+}
+
+type trackedGame struct {
+	last      game.Game
+	seen      time.Time
+	decisions int
+}
+
+// GameSummary is the state of a game as of the bot's latest decision in it.
+type GameSummary struct {
+	GameID         string    `json:"game_id"`
+	LastSeen       time.Time `json:"last_seen"`
+	Decisions      int       `json:"decisions"`
+	PotSize        int       `json:"pot_size"`
+	CurrentBet     int       `json:"current_bet"`
+	CommunityCards []string  `json:"community_cards"`
+	PlayersInHand  int       `json:"players_in_hand"`
+}
+
+// Observe records g as the latest state of its game.
+func (t *GameTracker) Observe(g game.Game) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.games == nil {
+		t.games = map[string]trackedGame{}
+	}
+	tg := t.games[g.GameID]
+	t.games[g.GameID] = trackedGame{last: g, seen: time.Now(), decisions: tg.decisions + 1}
+}
+
+// Active returns the IDs of the games seen within activeGameTTL, sorted, and
+// forgets the rest.
+func (t *GameTracker) Active() []string {
+	summaries := t.Summaries()
+	ids := make([]string, len(summaries))
+	for i, s := range summaries {
+		ids[i] = s.GameID
+	}
+	return ids
+}
+
+// Summaries describes the active games, sorted by ID, and forgets the rest.
+func (t *GameTracker) Summaries() []GameSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	summaries := []GameSummary{}
+	for id, tg := range t.games {
+		if time.Since(tg.seen) > activeGameTTL {
+			delete(t.games, id)
+			continue
+		}
+		s := GameSummary{
+			GameID:         id,
+			LastSeen:       tg.seen,
+			Decisions:      tg.decisions,
+			PotSize:        tg.last.PotSize,
+			CurrentBet:     tg.last.CurrentBet,
+			CommunityCards: []string{},
+		}
+		for _, card := range tg.last.CommunityCards {
+			s.CommunityCards = append(s.CommunityCards, card.String())
+		}
+		for _, player := range tg.last.PokerPlayers {
+			if player.IsPlayingHand {
+				s.PlayersInHand++
+			}
+		}
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].GameID < summaries[j].GameID })
+	return summaries
+}
+
+// Last returns the latest state seen of the game with the given ID.
+func (t *GameTracker) Last(id string) (game.Game, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tg, ok := t.games[id]
+	return tg.last, ok
+}
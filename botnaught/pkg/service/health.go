@@ -0,0 +1,68 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	poker "github.com/chehsunliu/poker"
+)
+
+// Version is reported by Health. Release builds set it with
+// -ldflags "-X go-poker-project/Botnaught/botnaught/pkg/service.Version=...".
+var Version = "dev"
+
+var startTime = time.Now()
+
+// HealthStatus is the detailed answer to a health check. Live means the
+// process is up and answering; Ready means it can also play: the evaluator
+// works, decisions can be logged and the bot is registered.
+type HealthStatus struct {
+	Live          bool     `json:"live"`
+	Ready         bool     `json:"ready"`
+	Registered    bool     `json:"registered"`
+	EvaluatorOK   bool     `json:"evaluator_ok"`
+	DecisionLogOK bool     `json:"decision_log_ok"`
+	ActiveGames   int      `json:"active_games"`
+	Uptime        string   `json:"uptime"`
+	Version       string   `json:"version"`
+	Problems      []string `json:"problems,omitempty"`
+}
+
+// newHealthStatus returns a live status carrying the process-wide fields.
+func newHealthStatus() HealthStatus {
+	return HealthStatus{
+		Live:    true,
+		Uptime:  time.Since(startTime).Round(time.Second).String(),
+		Version: Version,
+	}
+}
+
+// evaluatorSelfTest evaluates hands whose ranks are fixed: the royal flush is
+// the best possible hand and 7-5-4-3-2 offsuit the worst.
+func evaluatorSelfTest() bool {
+	best := []poker.Card{poker.NewCard("As"), poker.NewCard("Ks"), poker.NewCard("Qs"), poker.NewCard("Js"), poker.NewCard("Ts")}
+	worst := []poker.Card{poker.NewCard("7s"), poker.NewCard("5h"), poker.NewCard("4d"), poker.NewCard("3c"), poker.NewCard("2s")}
+	return poker.Evaluate(best) == 1 && poker.Evaluate(worst) == 7462
+}
+
+// Registration records the outcome of registering a bot with the game server.
+type Registration struct {
+	mu         sync.Mutex
+	registered bool
+	err        error
+}
+
+// Set records the result of a registration attempt.
+func (r *Registration) Set(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.registered = err == nil
+	r.err = err
+}
+
+// State returns whether the bot is registered and the last registration error.
+func (r *Registration) State() (registered bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.registered, r.err
+}
@@ -1,4 +1,113 @@
 package service
 
+import (
+	"context"
+	"sync/atomic"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+)
+
 // Middleware describes a service middleware.
 type Middleware func(BotnaughtService) BotnaughtService
+
+// RegistrationMiddleware reports reg's state in Health; an unregistered bot
+// is never ready because the game server won't send it any hands.
+func RegistrationMiddleware(reg *Registration) Middleware {
+	return func(next BotnaughtService) BotnaughtService {
+		return &registrationMiddleware{reg: reg, next: next}
+	}
+}
+
+type registrationMiddleware struct {
+	reg  *Registration
+	next BotnaughtService
+}
+
+func (r registrationMiddleware) Health(ctx context.Context) (status HealthStatus, err error) {
+	status, err = r.next.Health(ctx)
+	registered, regErr := r.reg.State()
+	status.Registered = registered
+	if !registered {
+		status.Ready = false
+		problem := "not registered with the game server"
+		if regErr != nil {
+			problem += ": " + regErr.Error()
+		}
+		status.Problems = append(status.Problems, problem)
+	}
+	return status, err
+}
+func (r registrationMiddleware) Action(ctx context.Context, curGame game.Game) (action game.Action, err error) {
+	return r.next.Action(ctx, curGame)
+}
+
+// TrackingMiddleware records every game the bot acts in on games and reports
+// how many are active in Health.
+func TrackingMiddleware(games *GameTracker) Middleware {
+	return func(next BotnaughtService) BotnaughtService {
+		return &trackingMiddleware{games: games, next: next}
+	}
+}
+
+type trackingMiddleware struct {
+	games *GameTracker
+	next  BotnaughtService
+}
+
+func (t trackingMiddleware) Health(ctx context.Context) (status HealthStatus, err error) {
+	status, err = t.next.Health(ctx)
+	status.ActiveGames = len(t.games.Active())
+	return status, err
+}
+func (t trackingMiddleware) Action(ctx context.Context, curGame game.Game) (action game.Action, err error) {
+	t.games.Observe(curGame)
+	return t.next.Action(ctx, curGame)
+}
+
+// PassiveSwitch turns a bot's passive mode on and off. The zero value is off.
+type PassiveSwitch struct {
+	on int32
+}
+
+// Set turns passive mode on or off.
+func (p *PassiveSwitch) Set(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&p.on, v)
+}
+
+// On reports whether passive mode is on.
+func (p *PassiveSwitch) On() bool {
+	return atomic.LoadInt32(&p.on) == 1
+}
+
+// PassiveMiddleware makes the bot check when it can and fold otherwise while
+// sw is on, without consulting the strategy.
+func PassiveMiddleware(sw *PassiveSwitch) Middleware {
+	return func(next BotnaughtService) BotnaughtService {
+		return &passiveMiddleware{sw: sw, next: next}
+	}
+}
+
+type passiveMiddleware struct {
+	sw   *PassiveSwitch
+	next BotnaughtService
+}
+
+func (p passiveMiddleware) Health(ctx context.Context) (status HealthStatus, err error) {
+	return p.next.Health(ctx)
+}
+func (p passiveMiddleware) Action(ctx context.Context, curGame game.Game) (action game.Action, err error) {
+	if !p.sw.On() {
+		return p.next.Action(ctx, curGame)
+	}
+	action.SelectedAction = "fold"
+	for _, available := range curGame.AvailableActions {
+		if available == "check" {
+			action.SelectedAction = "check"
+		}
+	}
+	return action, nil
+}
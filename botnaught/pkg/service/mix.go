@@ -0,0 +1,129 @@
+package service
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Choice is one action Bet may take, in Bet's terms: -1 folds, 0 checks or
+// calls and a positive amount raises. P is its probability.
+type Choice struct {
+	Bet int
+	P   float64
+}
+
+// Mix is a distribution over the actions Bet may take. Its probabilities
+// sum to 1.
+type Mix []Choice
+
+// Pure returns the Mix that always takes bet.
+func Pure(bet int) Mix {
+	return Mix{{bet, 1}}
+}
+
+// Sample draws an action from m with rnd.
+func (m Mix) Sample(rnd *rand.Rand) int {
+	x := rnd.Float64()
+	for _, c := range m {
+		if x < c.P {
+			return c.Bet
+		}
+		x -= c.P
+	}
+	return m[len(m)-1].Bet
+}
+
+// With returns m with p of its probability moved onto bet, taken from each
+// choice in proportion to its own.
+func (m Mix) With(bet int, p float64) Mix {
+	if p <= 0 {
+		return m
+	}
+	mixed := make(Mix, 0, len(m)+1)
+	found := false
+	for _, c := range m {
+		c.P *= 1 - p
+		if c.Bet == bet {
+			c.P += p
+			found = true
+		}
+		mixed = append(mixed, c)
+	}
+	if !found {
+		mixed = append(mixed, Choice{bet, p})
+	}
+	return mixed
+}
+
+func (m Mix) String() string {
+	parts := make([]string, len(m))
+	for i, c := range m {
+		action := "raise " + strconv.Itoa(c.Bet)
+		switch {
+		case c.Bet < 0:
+			action = "fold"
+		case c.Bet == 0:
+			action = "call"
+		}
+		parts[i] = action + " " + strconv.FormatFloat(c.P, 'f', 3, 64)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mix spreads Bet's pure choice of myBet into a Mix, so that the same state
+// doesn't always get the same action:
+//
+//   - Raises are split between their size and the other sizes, by
+//     params.SizeMix.
+//   - Value raises on the flop and turn are slowplayed, checked or called,
+//     params.Slowplay of the time.
+//   - Checks after the flop are turned into bluffs of bluff chips
+//     params.Bluff of the time.
+func mix(params BetParams, myBet int, sizes []int, value bool, bluff int, slowplay bool) Mix {
+	m := Pure(myBet)
+	if len(sizes) > 0 {
+		m[0].P = 1 - params.SizeMix
+		for _, b := range sizes {
+			m = append(m, Choice{b, params.SizeMix / float64(len(sizes))})
+		}
+	}
+	if myBet > 0 && value && slowplay {
+		m = m.With(0, params.Slowplay)
+	}
+	if myBet == 0 && bluff > 0 {
+		m = m.With(bluff, params.Bluff)
+	}
+	return m
+}
+
+// Seeds hands out the seed of each decision, which is logged with it so that
+// the decision can be replayed. The zero value seeds from the clock; Reset
+// makes the sequence repeatable.
+type Seeds struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// Reset makes the seeds that follow a sequence drawn from seed, or from the
+// clock when seed is 0.
+func (s *Seeds) Reset(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s.rnd = rand.New(rand.NewSource(seed))
+}
+
+// Next returns the seed of the next decision.
+func (s *Seeds) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rnd == nil {
+		s.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return s.rnd.Int63()
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// BetParams holds the thresholds Bet plays by. Fractions are of the chips we
+// control (stack plus chips committed this action); ranks are the evaluator's
+// 1 (royal flush) to 7462 (seven high) scale.
+type BetParams struct {
+	PreflopRaise      float64 `json:"preflop_raise" yaml:"preflop_raise"`
+	PreflopCall       float64 `json:"preflop_call" yaml:"preflop_call"`
+	AllInStrength     float64 `json:"all_in_strength" yaml:"all_in_strength"`
+	FlopAggression    float64 `json:"flop_aggression" yaml:"flop_aggression"`
+	TurnAggression    float64 `json:"turn_aggression" yaml:"turn_aggression"`
+	RiverAggression   float64 `json:"river_aggression" yaml:"river_aggression"`
+	MinLead           float64 `json:"min_lead" yaml:"min_lead"`
+	BigLead           float64 `json:"big_lead" yaml:"big_lead"`
+	BigLeadMultiplier float64 `json:"big_lead_multiplier" yaml:"big_lead_multiplier"`
+	CallRankCutoff    float64 `json:"call_rank_cutoff" yaml:"call_rank_cutoff"`
+	SemiBluffOuts     float64 `json:"semi_bluff_outs" yaml:"semi_bluff_outs"`
+	DrawCall          float64 `json:"draw_call" yaml:"draw_call"`
+	CbetDrySize       float64 `json:"cbet_dry_size" yaml:"cbet_dry_size"`
+	CbetWetSize       float64 `json:"cbet_wet_size" yaml:"cbet_wet_size"`
+	CbetWetStrength   float64 `json:"cbet_wet_strength" yaml:"cbet_wet_strength"`
+	RangeFold         float64 `json:"range_fold" yaml:"range_fold"`
+	BluffCatch        float64 `json:"bluff_catch" yaml:"bluff_catch"`
+	Exploit           float64 `json:"exploit" yaml:"exploit"`
+	Bluff             float64 `json:"bluff" yaml:"bluff"`
+	Slowplay          float64 `json:"slowplay" yaml:"slowplay"`
+	SizeMix           float64 `json:"size_mix" yaml:"size_mix"`
+}
+
+// DefaultBetParams returns the hand-picked values Bet has always used.
+func DefaultBetParams() BetParams {
+	return BetParams{
+		PreflopRaise:      .20,
+		PreflopCall:       .60,
+		AllInStrength:     .7,
+		FlopAggression:    .4,
+		TurnAggression:    .45,
+		RiverAggression:   .5,
+		MinLead:           10,
+		BigLead:           500,
+		BigLeadMultiplier: 1.5,
+		CallRankCutoff:    5000,
+		SemiBluffOuts:     8,
+		DrawCall:          1,
+		CbetDrySize:       .33,
+		CbetWetSize:       .75,
+		CbetWetStrength:   .35,
+		RangeFold:         .4,
+		BluffCatch:        .4,
+		Exploit:           1,
+		Bluff:             .1,
+		Slowplay:          .15,
+		SizeMix:           .3,
+	}
+}
+
+// BetParamSpec describes one of the BetParams: its name in parameter maps,
+// what it does and the range of values it accepts.
+type BetParamSpec struct {
+	Name     string
+	Usage    string
+	Min, Max float64
+	field    func(p *BetParams) *float64
+}
+
+// Field returns a pointer to the parameter's value in p.
+func (s BetParamSpec) Field(p *BetParams) *float64 {
+	return s.field(p)
+}
+
+// BetParamSpecs lists every BetParams field in declaration order.
+var BetParamSpecs = []BetParamSpec{
+	{"preflop_raise", "Pre-flop, raise our good hands while the bet is below this fraction of our chips; the pot sizes the raise", 0, .5, func(p *BetParams) *float64 { return &p.PreflopRaise }},
+	{"preflop_call", "Pre-flop, call bets below this fraction of our chips", 0, 1, func(p *BetParams) *float64 { return &p.PreflopCall }},
+	{"all_in_strength", "Go all in above this hand strength when ahead of the board", 0, 1, func(p *BetParams) *float64 { return &p.AllInStrength }},
+	{"flop_aggression", "Raise on the flop above this hand strength, while the bet is below that fraction of our chips; the pot and board size the raise", .2, 1, func(p *BetParams) *float64 { return &p.FlopAggression }},
+	{"turn_aggression", "Raise on the turn above this hand strength, while the bet is below that fraction of our chips; the pot and board size the raise", .2, 1, func(p *BetParams) *float64 { return &p.TurnAggression }},
+	{"river_aggression", "Raise on the river above this hand strength, while the bet is below that fraction of our chips; the pot and board size the raise", .2, 1, func(p *BetParams) *float64 { return &p.RiverAggression }},
+	{"min_lead", "Ranks our hand must lead the board by before betting big", 0, 7462, func(p *BetParams) *float64 { return &p.MinLead }},
+	{"big_lead", "Ranks of lead over the board that multiply our bet", 0, 7462, func(p *BetParams) *float64 { return &p.BigLead }},
+	{"big_lead_multiplier", "Multiplies the bet we are willing to face when our lead exceeds big_lead, turning calls into raises the pot sizes", 1, 3, func(p *BetParams) *float64 { return &p.BigLeadMultiplier }},
+	{"call_rank_cutoff", "Call bets we wouldn't make ourselves with a rank below this", 1, 7462, func(p *BetParams) *float64 { return &p.CallRankCutoff }},
+	{"semi_bluff_outs", "On the flop and turn, bet draws with at least this many clean outs", 1, 25, func(p *BetParams) *float64 { return &p.SemiBluffOuts }},
+	{"draw_call", "On the flop and turn, call up to our draw equity times this, as a fraction of our chips", 0, 3, func(p *BetParams) *float64 { return &p.DrawCall }},
+	{"cbet_dry_size", "Continuation bet on dry flops, as a fraction of the pot", 0, 2, func(p *BetParams) *float64 { return &p.CbetDrySize }},
+	{"cbet_wet_size", "Continuation bet on wet flops, as a fraction of the pot", 0, 2, func(p *BetParams) *float64 { return &p.CbetWetSize }},
+	{"cbet_wet_strength", "On wet flops, continuation bet only above this hand strength", 0, 1, func(p *BetParams) *float64 { return &p.CbetWetStrength }},
+	{"range_fold", "On the river, fold to a re-raise from a player who isn't loose below this strength against their range, whatever our rank", 0, 1, func(p *BetParams) *float64 { return &p.RangeFold }},
+	{"bluff_catch", "Call instead of folding to the re-raiser's range when at least this share of their shown-down river bets were bluffs", 0, 1, func(p *BetParams) *float64 { return &p.BluffCatch }},
+	{"exploit", "Scale of the adjustments to each opponent's tendencies; 0 plays everyone alike", 0, 2, func(p *BetParams) *float64 { return &p.Exploit }},
+	{"bluff", "After the flop, bet this share of the hands we would check", 0, 1, func(p *BetParams) *float64 { return &p.Bluff }},
+	{"slowplay", "On the flop and turn, check or call this share of the hands we would bet for value", 0, 1, func(p *BetParams) *float64 { return &p.Slowplay }},
+	{"size_mix", "Share of raises made a quarter of their pot fraction smaller or larger", 0, 1, func(p *BetParams) *float64 { return &p.SizeMix }},
+}
+
+// Set changes the named parameter.
+func (p *BetParams) Set(name string, value float64) error {
+	for _, spec := range BetParamSpecs {
+		if spec.Name == name {
+			*spec.Field(p) = value
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown bet parameter %q", name)
+}
+
+// Apply returns p with every parameter in values changed.
+func (p BetParams) Apply(values map[string]float64) (BetParams, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := p.Set(name, values[name]); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// Validate reports the first parameter that is outside its range.
+func (p BetParams) Validate() error {
+	for _, spec := range BetParamSpecs {
+		if v := *spec.Field(&p); v < spec.Min || v > spec.Max {
+			return fmt.Errorf("bet parameter %s = %v is outside [%v, %v]", spec.Name, v, spec.Min, spec.Max)
+		}
+	}
+	return nil
+}
+
+// VersionedParams is a set of BetParams and the version number it was stored
+// under. Every decision logs the version it was made with.
+type VersionedParams struct {
+	Version int64     `json:"version"`
+	Params  BetParams `json:"params"`
+}
+
+// ParamStore holds a bot's current BetParams. Store swaps in a new set
+// atomically, so a decision in flight finishes with the set it started with.
+// The zero value holds DefaultBetParams at version 0.
+type ParamStore struct {
+	mu      sync.Mutex
+	current atomic.Value
+}
+
+// Load returns the current parameters.
+func (s *ParamStore) Load() VersionedParams {
+	if v, ok := s.current.Load().(VersionedParams); ok {
+		return v
+	}
+	return VersionedParams{Params: DefaultBetParams()}
+}
+
+// Store validates p and makes it the current set under the next version.
+func (s *ParamStore) Store(p BetParams) (VersionedParams, error) {
+	if err := p.Validate(); err != nil {
+		return VersionedParams{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := VersionedParams{Version: s.Load().Version + 1, Params: p}
+	s.current.Store(v)
+	return v, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	cfr "go-poker-project/Botnaught/botnaught/pkg/cfr"
+)
+
+// Policy holds a CFR strategy table for a bot to play from wherever the
+// table covers the game. The zero value holds no table.
+type Policy struct {
+	mu    sync.Mutex
+	table *cfr.Table
+	rng   *rand.Rand
+}
+
+// Set makes t the table played from; nil stops playing from a table.
+func (p *Policy) Set(t *cfr.Table) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.table = t
+	if p.rng == nil {
+		p.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
+// Act returns the table's action in g and the abstract move it came from.
+// ok is false when there is no table or it doesn't cover g.
+func (p *Policy) Act(g game.Game) (action game.Action, move byte, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.table == nil {
+		return action, 0, false
+	}
+	return p.table.Act(g, p.rng)
+}
+
+// PolicyPlayer is implemented by strategies that can play from a CFR
+// strategy table.
+type PolicyPlayer interface {
+	Policy() *Policy
+}
@@ -4,33 +4,55 @@ import (
 	//"bufio"
 	"context"
 	"math"
+	"math/rand"
 	//"fmt"
 	"log"
-	"os"
 	"strconv"
-	//"strings"
+	"strings"
 
 	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
 	poker "github.com/chehsunliu/poker"
+	draws "go-poker-project/Botnaught/botnaught/pkg/draws"
+	handeval "go-poker-project/Botnaught/botnaught/pkg/handeval"
+	madehand "go-poker-project/Botnaught/botnaught/pkg/madehand"
+	opponent "go-poker-project/Botnaught/botnaught/pkg/opponent"
+	showdown "go-poker-project/Botnaught/botnaught/pkg/showdown"
+	sizing "go-poker-project/Botnaught/botnaught/pkg/sizing"
+	texture "go-poker-project/Botnaught/botnaught/pkg/texture"
 )
 
 // BotnaughtService describes the service.
 type BotnaughtService interface {
-	Health(ctx context.Context) (err error)
+	Health(ctx context.Context) (status HealthStatus, err error)
 	Action(ctx context.Context, game game.Game) (action game.Action, err error)
 }
 
 type basicBotnaughtService struct{
 	curGameID string
+	decisions DecisionLog
+	params    ParamStore
+	policy    Policy
+	cache     DecisionCache
+	showdowns showdown.Collector
+	opponents opponent.Tracker
+	seeds     Seeds
 }
 
-func (b *basicBotnaughtService) Health(ctx context.Context) (err error) {
-	return err
+func (b *basicBotnaughtService) Health(ctx context.Context) (status HealthStatus, err error) {
+	status = newHealthStatus()
+	status.EvaluatorOK = evaluatorSelfTest()
+	if !status.EvaluatorOK {
+		status.Problems = append(status.Problems, "evaluator self-test failed")
+	}
+	status.DecisionLogOK = b.decisions.Writable()
+	if !status.DecisionLogOK {
+		status.Problems = append(status.Problems, "decision log "+b.decisions.Path()+" is not writable")
+	}
+	status.Ready = status.EvaluatorOK && status.DecisionLogOK
+	return status, err
 }
 func (b *basicBotnaughtService) Action(ctx context.Context, curGame game.Game) (action game.Action, err error) {
-
-	f, err := os.OpenFile("botnaught-log.log",
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := b.decisions.Open()
 	if err != nil {
 		log.Println(err)
 	}
@@ -63,6 +85,8 @@ func (b *basicBotnaughtService) Action(ctx context.Context, curGame game.Game) (
 			break
 		}
 	}
+	params := b.params.Load()
+	logger.Println("Params version: " + strconv.FormatInt(params.Version, 10))
 	logger.Println("Player:" + myPlayer.Name)
 	logger.Println("My Rank:" + strconv.Itoa(myPlayer.HandRankInt))
 	logger.Println("My Hand:")
@@ -73,8 +97,39 @@ func (b *basicBotnaughtService) Action(ctx context.Context, curGame game.Game) (
 		logger.Print(card.String() + ", ")
 	}
 
-	switch myBet := 
-			Bet(myPlayer.HoleCards,myPlayer.HandRankInt,myPlayer.Chips,myPlayer.ChipsCommittedThisAction,curGame.CurrentBet,curGame.CommunityCards,logger); {		
+	found, err := b.showdowns.Observe(curGame.GameID, curGame.HandLog)
+	if err != nil {
+		logger.Println("Storing showdowns: ", err)
+		err = nil
+	}
+	for _, r := range found {
+		logger.Println("Showdown: " + r.Player + " showed " + r.Hole + " on " + r.Board + ", bluff: " + strconv.FormatBool(r.Bluff))
+	}
+	b.opponents.Observe(curGame.GameID, curGame.HandLog)
+
+	key := DecisionKey(curGame, params.Version, b.opponents.Version(), b.showdowns.Version())
+	if cached, ok := b.cache.Get(key); ok {
+		logger.Println("Returning cached action ", cached)
+		return cached, err
+	}
+	if policyAction, move, ok := b.policy.Act(curGame); ok {
+		logger.Println("CFR move " + string(move) + ", returning action ", policyAction)
+		b.cache.Put(key, policyAction)
+		return policyAction, err
+	}
+
+	situation := NewSituation(curGame, myPlayer)
+	situation.Showdowns = b.showdowns.Stats(situation.Opponent)
+	situation.Tendencies = b.opponents.Stats(situation.Opponent)
+	betParams, why := Exploit(params.Params, situation.Tendencies, situation)
+	for _, reason := range why {
+		logger.Println("Against " + situation.Opponent + ": " + reason)
+	}
+	mixed := Bet(betParams,myPlayer.HoleCards,myPlayer.HandRankInt,myPlayer.Chips,myPlayer.ChipsCommittedThisAction,curGame.CurrentBet,curGame.CommunityCards,situation,logger)
+	seed := b.seeds.Next()
+	logger.Println("Mix: " + mixed.String())
+	logger.Println("Decision seed: " + strconv.FormatInt(seed, 10))
+	switch myBet := mixed.Sample(rand.New(rand.NewSource(seed))); {		
 		case myBet < 0:
 			// FOLD!
 			action.SelectedAction = "fold"
@@ -88,14 +143,61 @@ func (b *basicBotnaughtService) Action(ctx context.Context, curGame game.Game) (
 	}
 
 	logger.Println("Returning action ", action)
+	b.cache.Put(key, action)
 	return action, err
 }
 
+// DecisionLog implements DecisionLogger.
+func (b *basicBotnaughtService) DecisionLog() *DecisionLog {
+	return &b.decisions
+}
+
+// ParamStore implements Tunable.
+func (b *basicBotnaughtService) ParamStore() *ParamStore {
+	return &b.params
+}
+
+// Policy implements PolicyPlayer.
+func (b *basicBotnaughtService) Policy() *Policy {
+	return &b.policy
+}
+
+// DecisionCache implements DecisionCacher.
+func (b *basicBotnaughtService) DecisionCache() *DecisionCache {
+	return &b.cache
+}
+
+// Showdowns implements ShowdownCollector.
+func (b *basicBotnaughtService) Showdowns() *showdown.Collector {
+	return &b.showdowns
+}
+
+// Seeds implements Seeder.
+func (b *basicBotnaughtService) Seeds() *Seeds {
+	return &b.seeds
+}
+
 // NewBasicBotnaughtService returns a naive, stateless implementation of BotnaughtService.
 func NewBasicBotnaughtService() BotnaughtService {
 	return &basicBotnaughtService{}
 }
 
+// newBasicStrategy is the StrategyFactory for the "basic" strategy: Bet
+// played with base overridden by params. Each bot gets its own decision log
+// so that bots sharing a process don't interleave; its showdown records stay
+// in memory unless its host opens a file for them.
+func newBasicStrategy(name string, base BetParams, params map[string]float64) (BotnaughtService, error) {
+	betParams, err := base.Apply(params)
+	if err != nil {
+		return nil, err
+	}
+	b := &basicBotnaughtService{decisions: DecisionLog{path: strings.ToLower(name) + "-log.log"}}
+	if _, err := b.params.Store(betParams); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
 // New returns a BotnaughtService with all of the expected middleware wired in.
 func New(middleware []Middleware) BotnaughtService {
 	var svc BotnaughtService = NewBasicBotnaughtService()
@@ -105,9 +207,30 @@ func New(middleware []Middleware) BotnaughtService {
 	return svc
 }
 
-// Bet - betting function based on input variables
-func Bet(myCards []poker.Card, myRank int, myChips int, myCommitted int, currentBet int, communityCards []poker.Card, logger *log.Logger) (int) {
+// bluffCatchSample is how many shown-down river bets an opponent needs
+// before Bet trusts their bluff frequency.
+const bluffCatchSample = 5
+
+// An opponent who plays more than loosePlayRate of at least looseSample
+// hands is loose: their re-raises on the river are called rather than
+// folded to, whatever their seat says of their range.
+const (
+	looseSample   = 10
+	loosePlayRate = .4
+)
+
+// strengthSamples is how many opponent hands Bet works out hand potential
+// against, which keeps a decision to a few milliseconds.
+const strengthSamples = 150
+
+// Bet - betting function based on input variables. It returns the mix of
+// actions to sample from.
+func Bet(params BetParams, myCards []poker.Card, myRank int, myChips int, myCommitted int, currentBet int, communityCards []poker.Card, situation Situation, logger *log.Logger) (Mix) {
 	myBet := -1
+	value := false // whether myBet is a bet for value
+	bluff := 0     // what to bluff with when checked to
+	fraction := 0.0 // the share of the pot to raise by, when raising
+	spot := sizing.Spot{Pot: situation.PotSize, CurrentBet: currentBet, Committed: myCommitted, Chips: myChips, LastRaise: situation.LastRaise, BigBlind: situation.BigBlind}
 	myTotal := myChips + myCommitted
 	availChips := myTotal - currentBet
 	// ex: 40 chips + 30 committed - 50 current bet = 20 avail
@@ -117,6 +240,19 @@ func Bet(myCards []poker.Card, myRank int, myChips int, myCommitted int, current
 		rankPct = 1.0 - (float64(myRank)/7462.0)
 		logger.Println("My rank / rank percentage: " + strconv.Itoa(myRank) + ", " + strconv.FormatFloat(rankPct, 'f', 3, 64))
 	}
+	if len(communityCards) >= 3 {
+		// Strength against the hands still in, counting what the next card may bring
+		opts := handeval.Options{Opponents: situation.Opponents, Samples: strengthSamples}
+		if situation.Range != nil {
+			logger.Println("Weighing opponent hands by their range: " + strconv.FormatFloat(situation.Range.Combos(), 'f', 1, 64) + " combos")
+			opts.Weighting = situation.Range
+		}
+		strength := handeval.Evaluate(myCards, communityCards, opts)
+		rankPct = strength.EHS
+		logger.Println("HSn / PPot / NPot / EHS / EHS2: " + strconv.FormatFloat(strength.HSn, 'f', 3, 64) + ", " +
+			strconv.FormatFloat(strength.PPot, 'f', 3, 64) + ", " + strconv.FormatFloat(strength.NPot, 'f', 3, 64) + ", " +
+			strconv.FormatFloat(strength.EHS, 'f', 3, 64) + ", " + strconv.FormatFloat(strength.EHS2, 'f', 3, 64))
+	}
 	logger.Println("Current bet: " + strconv.Itoa(currentBet))
 	logger.Println("Current chips: " + strconv.Itoa(myChips) + " - Committed chips: " + strconv.Itoa(myCommitted))
 
@@ -131,9 +267,10 @@ func Bet(myCards []poker.Card, myRank int, myChips int, myCommitted int, current
 		if len(communityCards) == 0 {
 			// Raise if we have a Pair, Ace or suited K/Q
 			if checkRaise(myCards) {
-				myBet = int(math.Round(float64(myTotal) * .20))
+				myBet = int(math.Round(float64(myTotal) * params.PreflopRaise))
+				fraction = sizing.Choose(communityCards, rankPct, spot.SPR()).Fraction()
 			} else {
-				if float32(currentBet) < float32(myTotal) * .60 {
+				if float64(currentBet) < float64(myTotal) * params.PreflopCall {
 					// Call
 					myBet = currentBet
 				}
@@ -157,48 +294,115 @@ func Bet(myCards []poker.Card, myRank int, myChips int, myCommitted int, current
 					myHandLead = turnScore1 - int32(myRank)
 				}
 			}
+			if flop {
+				// The board can't be scored alone, so ask what our cards add to it
+				made := madehand.Classify(myCards, communityCards)
+				logger.Println("Made hand: " + made.String())
+				// Pairs below top pair are as often behind as ahead, so don't lead with them
+				if made.Class >= madehand.TopPair {
+					myHandLead = madehand.Lead(myCards, communityCards)
+				}
+			}
 			
 			logger.Println("myHandLead: " + strconv.Itoa(int(myHandLead)))
+			board := texture.Analyze(communityCards)
+			logger.Println("Board: " + board.String())
+			if turn || river {
+				logger.Println("Board change: " + texture.Compare(communityCards).String())
+			}
+			if currentBet == 0 {
+				bluff = spot.To(params.CbetDrySize)
+			}
+			var draw draws.Analysis
+			drawEquity := 0.0
+			if flop || turn {
+				draw = draws.Analyze(myCards, communityCards)
+				drawEquity = draw.Equity(communityCards)
+				logger.Println("Draws: " + draw.String() + " - equity " + strconv.FormatFloat(drawEquity, 'f', 3, 64))
+			}
 			switch rankPct := rankPct; {
-				case rankPct > .7 && myHandLead > 10:
+				case rankPct > params.AllInStrength && float64(myHandLead) > params.MinLead:
 					// ALL IN
 					logger.Println("all in")
+					value = true
 					myBet = myTotal
-				case rankPct > .4 && flop:
+					fraction = sizing.AllIn.Fraction()
+				case rankPct > params.FlopAggression && flop && float64(myHandLead) > params.MinLead:
 					//Bid aggressively FLOP
 					logger.Println("aggressive flop")
+					value = true
 					myBet = int(math.Round(float64(myTotal) * rankPct))
-				case rankPct > .45 && turn && myHandLead > 10:
+					fraction = sizing.Choose(communityCards, rankPct, spot.SPR()).Fraction()
+				case rankPct > params.TurnAggression && turn && float64(myHandLead) > params.MinLead:
 					//Bid aggressively TURN
 					logger.Println("aggressive turn")
+					value = true
 					myBet = int(math.Round(float64(myTotal) * rankPct))
-				case rankPct > .5 && river && myHandLead > 10:
+					fraction = sizing.Choose(communityCards, rankPct, spot.SPR()).Fraction()
+				case rankPct > params.RiverAggression && river && float64(myHandLead) > params.MinLead:
 					//Bid aggressively RIVER
 					logger.Println("aggressive river")
+					value = true
 					myBet = int(math.Round(float64(myTotal) * rankPct))
+					fraction = sizing.Choose(communityCards, rankPct, spot.SPR()).Fraction()
+				case flop && situation.Aggressor && currentBet == 0 && (!board.Wet || rankPct > params.CbetWetStrength):
+					// Continuation bet, bigger on boards that give draws
+					fraction = params.CbetDrySize
+					if board.Wet {
+						fraction = params.CbetWetSize
+					}
+					myBet = spot.To(fraction)
+					logger.Println("continuation bet")
 				default:
 					willing := int(math.Round(float64(myTotal) * rankPct))
+					drawing := int(math.Round(float64(myTotal) * drawEquity * params.DrawCall))
+					if drawing > willing {
+						willing = drawing
+					}
 					logger.Println("default bet. Max: " + strconv.Itoa(willing))
-					if willing >= currentBet {
+					if draw.Clean >= params.SemiBluffOuts && drawing > currentBet {
+						// Bet the draw as if its equity were made strength
+						logger.Println("semi-bluff")
+						myBet = drawing
+						fraction = sizing.Choose(communityCards, rankPct, spot.SPR()).Fraction()
+					} else if willing >= currentBet {
 						myBet = currentBet
 					}
 			}
 		}
 		logger.Println("Willing to bet: " + strconv.Itoa(myBet))
-		if myHandLead > 500 {
-			myBet = int(math.Round(float64(myBet) * 1.5))
-			logger.Println("Multiplied by 1.5: " + strconv.Itoa(myBet))
+		if float64(myHandLead) > params.BigLead {
+			myBet = int(math.Round(float64(myBet) * params.BigLeadMultiplier))
+			logger.Println("Multiplied by " + strconv.FormatFloat(params.BigLeadMultiplier, 'f', -1, 64) + ": " + strconv.Itoa(myBet))
+			if fraction == 0 {
+				// A call the lead turned into a raise is sized like any other
+				fraction = sizing.Choose(communityCards, rankPct, spot.SPR()).Fraction()
+			}
 		}
 		// if we try to bet more chips than we have
 		if myBet > myChips {
 			myBet = myChips
 		}
 		// if current bet is greater than what we're willing to bet
-		if (myBet < currentBet && myRank < 5000) {
+		if (myBet < currentBet && float64(myRank) < params.CallRankCutoff) {
 			myBet = currentBet
+			// unless a tight player re-raised us on the river with a range that beats us
+			bluffer := situation.Showdowns.RiverBets >= bluffCatchSample && situation.Showdowns.BluffFrequency() >= params.BluffCatch
+			loose := situation.Tendencies.Hands >= looseSample && situation.Tendencies.PlayRate() > loosePlayRate
+			if river && situation.OpponentReraised && situation.Range != nil && rankPct < params.RangeFold {
+				switch {
+				case bluffer:
+					logger.Println("calling: " + strconv.Itoa(situation.Showdowns.Bluffs) + " of their " + strconv.Itoa(situation.Showdowns.RiverBets) + " shown river bets were bluffs")
+				case loose:
+					logger.Println("calling: they played " + strconv.Itoa(situation.Tendencies.VPIP) + " of " + strconv.Itoa(situation.Tendencies.Hands) + " hands")
+				default:
+					logger.Println("folding to the re-raiser's range")
+					myBet = -1
+				}
+			}
 		}
 		// if current bet is greater than what we're willing to bet
-		if (myBet < currentBet && !(myRank < 5000)) {
+		if (myBet < currentBet && !(float64(myRank) < params.CallRankCutoff)) {
 			myBet = -1
 		}
 		// if we are only willing to match current bet
@@ -207,7 +411,24 @@ func Bet(myCards []poker.Card, myRank int, myChips int, myCommitted int, current
 		}
 	}
 
-	return myBet
+	// What we are willing to bet decides whether to raise; the pot decides how much
+	var sizes []int
+	if myBet > 0 {
+		myBet = spot.To(fraction)
+		logger.Println("Raise of " + strconv.FormatFloat(fraction, 'f', 2, 64) + " pot, SPR " + strconv.FormatFloat(spot.SPR(), 'f', 2, 64) + ": to " + strconv.Itoa(myBet))
+		if myBet <= currentBet {
+			// All we have only calls
+			myBet = 0
+		} else if !math.IsInf(fraction, 1) {
+			for _, f := range []float64{fraction * .75, fraction * 1.25} {
+				if to := spot.To(f); to != myBet && to > currentBet && (len(sizes) == 0 || to != sizes[0]) {
+					sizes = append(sizes, to)
+				}
+			}
+		}
+	}
+
+	return mix(params, myBet, sizes, value, bluff, len(communityCards) == 3 || len(communityCards) == 4)
 }
 
 func checkRaise(holeCards []poker.Card) (raiseIt bool) {
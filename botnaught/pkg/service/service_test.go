@@ -3,11 +3,20 @@ package service
 import (
 	"fmt"
 	"context"
+	"strconv"
 	"github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
 	"github.com/gSchool/golang-curriculum-c-6/server/pkg/player"
 	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	sizing "go-poker-project/Botnaught/botnaught/pkg/sizing"
+	opponent "go-poker-project/Botnaught/botnaught/pkg/opponent"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_basicBotnaughtService_Action(t *testing.T) {
@@ -34,7 +43,7 @@ func Test_basicBotnaughtService_Action(t *testing.T) {
 	}{
 		{
 			name: "PRE-FLOP: Check",
-			b: &basicBotnaughtService{},
+			b: unmixed(),
 			args: args{
 				game: game.Game{
 					GameID:          "CheckPreFlop",
@@ -83,7 +92,7 @@ func Test_basicBotnaughtService_Action(t *testing.T) {
 		},
 		{
 			name: "PRE-FLOP: Raise (Ace)",
-			b: &basicBotnaughtService{},
+			b: unmixed(),
 			args: args{
 				game: game.Game{
 					GameID:          "RaiseAce",
@@ -119,20 +128,20 @@ func Test_basicBotnaughtService_Action(t *testing.T) {
 					},
 					HandLog:          gm.HandLog,
 					AvailableActions: gm.AvailableActions,
-					PotSize:		  gm.PotSize,
+					PotSize:		  3,
 					CommunityCards:	  []poker.Card{},
-					CurrentBet:       0,
+					CurrentBet:       gm.BigBlind,
 					SmallBlind:       gm.SmallBlind,
 					BigBlind:         gm.BigBlind,
 					StartingStack:    gm.StartingStack,
 				},
 			},
-			wantAction: game.Action{SelectedAction: "raise", Value: 20},
+			wantAction: game.Action{SelectedAction: "raise", Value: 7}, // a pot-sized raise over the big blind
 			wantErr: false,
 		},
 		{
 			name: "PRE-FLOP: Raise (Suited Q/K)",
-			b: &basicBotnaughtService{},
+			b: unmixed(),
 			args: args{
 				game: game.Game{
 					GameID:          "Suited",
@@ -168,20 +177,20 @@ func Test_basicBotnaughtService_Action(t *testing.T) {
 					},
 					HandLog:          gm.HandLog,
 					AvailableActions: gm.AvailableActions,
-					PotSize:		  gm.PotSize,
+					PotSize:		  3,
 					CommunityCards:	  []poker.Card{},
-					CurrentBet:       0,
+					CurrentBet:       gm.BigBlind,
 					SmallBlind:       gm.SmallBlind,
 					BigBlind:         gm.BigBlind,
 					StartingStack:    gm.StartingStack,
 				},
 			},
-			wantAction: game.Action{SelectedAction: "raise", Value: 20},
+			wantAction: game.Action{SelectedAction: "raise", Value: 7}, // a pot-sized raise over the big blind
 			wantErr: false,
 		},
 		{
 			name: "FLOP: Call (Not great hand)",
-			b: &basicBotnaughtService{},
+			b: unmixed(),
 			args: args{
 				game: game.Game{
 					GameID:          "FlopCall",
@@ -233,7 +242,7 @@ func Test_basicBotnaughtService_Action(t *testing.T) {
 		},
 		{
 			name: "FLOP: Call (match bet)",
-			b: &basicBotnaughtService{},
+			b: unmixed(),
 			args: args{
 				game: game.Game{
 					GameID:          "FlopMatchCall",
@@ -632,7 +641,7 @@ func Test_basicBotnaughtService_Action(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &basicBotnaughtService{}
+			b := unmixed()
 			gotAction, err := b.Action(tt.args.ctx, tt.args.game)
 			gm, err = game.StartNewGame(p, 1, 2, 100)
 			if err != nil {
@@ -649,3 +658,565 @@ func Test_basicBotnaughtService_Action(t *testing.T) {
 		})
 	}
 }
+
+func Test_basicBotnaughtService_Health(t *testing.T) {
+	b := &basicBotnaughtService{}
+	games := &GameTracker{}
+	games.Observe(game.Game{GameID: "HealthGame"})
+	status, err := TrackingMiddleware(games)(b).Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Live || !status.Ready {
+		t.Errorf("basicBotnaughtService.Health() live = %v, ready = %v, problems %v", status.Live, status.Ready, status.Problems)
+	}
+	if !status.EvaluatorOK {
+		t.Error("basicBotnaughtService.Health() evaluator self-test failed")
+	}
+	if status.ActiveGames != 1 {
+		t.Errorf("basicBotnaughtService.Health() active games = %d, want 1", status.ActiveGames)
+	}
+
+	unregistered, err := RegistrationMiddleware(&Registration{})(b).Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unregistered.Ready || unregistered.Registered {
+		t.Errorf("unregistered bot reported ready = %v, registered = %v", unregistered.Ready, unregistered.Registered)
+	}
+}
+
+func TestParamStore(t *testing.T) {
+	var s ParamStore
+	if got := s.Load(); got.Version != 0 || got.Params != DefaultBetParams() {
+		t.Errorf("zero ParamStore.Load() = %+v, want defaults at version 0", got)
+	}
+	p := DefaultBetParams()
+	p.AllInStrength = .8
+	stored, err := s.Store(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Version != 1 || s.Load().Params.AllInStrength != .8 {
+		t.Errorf("ParamStore.Store() = %+v, want version 1 with all_in_strength .8", stored)
+	}
+	p.PreflopRaise = -1
+	if _, err := s.Store(p); err == nil {
+		t.Error("ParamStore.Store() accepted preflop_raise -1")
+	}
+	if s.Load().Version != 1 {
+		t.Errorf("rejected Store changed the version to %d", s.Load().Version)
+	}
+}
+
+func TestBaselineStrategies(t *testing.T) {
+	// Seven-deuce offsuit facing a pot-sized bet before the flop.
+	facingBet := game.Game{
+		GameID:           "baseline",
+		AvailableActions: []string{"fold", "call", "raise"},
+		PotSize:          9,
+		CurrentBet:       6,
+		BigBlind:         2,
+		PokerPlayers: []game.PokerPlayer{
+			{Name: "me", Chips: 98, ChipsCommittedThisAction: 2, IsPlayingHand: true,
+				HoleCards: []poker.Card{poker.NewCard("7h"), poker.NewCard("2c")}},
+			{Name: "them", Chips: 94, ChipsCommittedThisAction: 6, IsPlayingHand: true},
+		},
+	}
+	tests := []struct {
+		strategy string
+		params   map[string]float64
+		want     string
+	}{
+		{"call", nil, "call"},
+		{"raise", nil, "raise"},
+		{"random", map[string]float64{"seed": 1, "fold": 1, "raise": 0}, "fold"},
+		{"tight-passive", nil, "fold"},
+		{"loose-aggressive", map[string]float64{"call": 0}, "call"},
+		{"equity", map[string]float64{"seed": 1, "margin": 0.5}, "fold"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			bot, err := NewBot(BotDefinition{Name: "b", Strategy: tt.strategy, Params: tt.params}, DefaultBetParams(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			status, err := bot.Service.Health(context.Background())
+			if err != nil || !status.Ready {
+				t.Errorf("Health() = %+v, %v; want ready", status, err)
+			}
+			action, err := bot.Service.Action(context.Background(), facingBet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if action.SelectedAction != tt.want {
+				t.Errorf("Action() = %+v, want %s", action, tt.want)
+			}
+			if action.SelectedAction == "raise" && action.Value <= facingBet.CurrentBet {
+				t.Errorf("raised to %d, not above the current bet %d", action.Value, facingBet.CurrentBet)
+			}
+		})
+	}
+
+	if _, err := NewBot(BotDefinition{Name: "b", Strategy: "raise", Params: map[string]float64{"all_in_strength": 1}}, DefaultBetParams(), nil); err == nil {
+		t.Error("raise strategy accepted an unknown parameter")
+	}
+
+	// A small raise is made the minimum, and one all in can't make is a call
+	small, err := NewBot(BotDefinition{Name: "b", Strategy: "raise", Params: map[string]float64{"size": .01}}, DefaultBetParams(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reraised := facingBet
+	reraised.HandLog = []string{"-- hand 1 button me", "me small_blind 1", "them big_blind 2", "them raise 6"}
+	short := facingBet
+	short.PokerPlayers = []game.PokerPlayer{facingBet.PokerPlayers[0], facingBet.PokerPlayers[1]}
+	short.PokerPlayers[0].Chips = 3
+	for _, tt := range []struct {
+		g    game.Game
+		want game.Action
+	}{
+		{reraised, game.Action{SelectedAction: "raise", Value: 10}},
+		{short, game.Action{SelectedAction: "call"}},
+	} {
+		if got, err := small.Service.Action(context.Background(), tt.g); err != nil || got != tt.want {
+			t.Errorf("Action() = %+v, %v; want %+v", got, err, tt.want)
+		}
+	}
+}
+
+func TestDecisionCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := &DecisionCache{now: func() time.Time { return now }}
+	c.Configure(2, time.Minute, nil)
+	fold, call := game.Action{SelectedAction: "fold"}, game.Action{SelectedAction: "call"}
+	c.Put(1, fold)
+	c.Put(2, call)
+	if a, ok := c.Get(1); !ok || a != fold {
+		t.Errorf("Get(1) = %+v, %v; want fold", a, ok)
+	}
+	// 2 is now the least recently used and makes way for 3.
+	c.Put(3, call)
+	if _, ok := c.Get(2); ok {
+		t.Error("least recently used entry survived")
+	}
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get(1); ok {
+		t.Error("expired entry returned")
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 2 || s.Entries != 1 {
+		t.Errorf("Stats() = %+v", s)
+	}
+
+	g := game.Game{
+		AvailableActions: []string{"fold", "call", "raise"},
+		PotSize:          9,
+		CurrentBet:       6,
+		BigBlind:         2,
+		CommunityCards:   []poker.Card{poker.NewCard("2s"), poker.NewCard("7s"), poker.NewCard("9d")},
+		PokerPlayers: []game.PokerPlayer{
+			{Name: "me", Chips: 98, ChipsCommittedThisAction: 2, IsPlayingHand: true,
+				HoleCards: []poker.Card{poker.NewCard("As"), poker.NewCard("Ks")}},
+			{Name: "them", Chips: 94, ChipsCommittedThisAction: 6, IsPlayingHand: true},
+		},
+	}
+	renamed := g
+	renamed.CommunityCards = []poker.Card{poker.NewCard("2h"), poker.NewCard("7h"), poker.NewCard("9c")}
+	renamed.PokerPlayers = []game.PokerPlayer{g.PokerPlayers[0], g.PokerPlayers[1]}
+	renamed.PokerPlayers[0].HoleCards = []poker.Card{poker.NewCard("Ah"), poker.NewCard("Kh")}
+	if DecisionKey(g, 1) != DecisionKey(renamed, 1) {
+		t.Error("states equal up to suits have different keys")
+	}
+	if DecisionKey(g, 1) == DecisionKey(g, 2) {
+		t.Error("new parameters didn't change the key")
+	}
+	if DecisionKey(g, 1, 0, 0) == DecisionKey(g, 1, 1, 0) {
+		t.Error("new opponent stats didn't change the key")
+	}
+	bigger := g
+	bigger.CurrentBet = 8
+	if DecisionKey(g, 1) == DecisionKey(bigger, 1) {
+		t.Error("a bigger bet didn't change the key")
+	}
+	another, later := g, g
+	another.GameID = "another"
+	later.HandLog = []string{"-- hand 2 button me"}
+	if DecisionKey(g, 1) == DecisionKey(another, 1) || DecisionKey(g, 1) == DecisionKey(later, 1) {
+		t.Error("the same spot in another game or hand has the same key")
+	}
+
+	bot, err := NewBot(BotDefinition{Name: "cached", Strategy: "basic"}, DefaultBetParams(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot.Log.Discard()
+	bot.Cache.Configure(10, time.Minute, nil)
+	first, _ := bot.Service.Action(context.Background(), g)
+	second, _ := bot.Service.Action(context.Background(), renamed)
+	if first != second || bot.Cache.Stats().Hits != 1 {
+		t.Errorf("repeat got %+v after %+v with %+v", second, first, bot.Cache.Stats())
+	}
+}
+
+// headsUpFlop is a heads-up game on the flop board, after raiser raised to
+// 10 before it and the other player called, with me holding hole and to act.
+func headsUpFlop(hole, board, raiser string) game.Game {
+	caller := "them"
+	if raiser == "them" {
+		caller = "me"
+	}
+	g := game.Game{
+		AvailableActions: []string{"fold", "check", "raise"},
+		PotSize:          20,
+		BigBlind:         2,
+		HandLog: []string{
+			"-- hand 1 button me",
+			"me small_blind 1",
+			"them big_blind 2",
+			raiser + " raise 10",
+			caller + " call 10",
+			"-- flop " + board,
+		},
+		CommunityCards: cards.MustParse(board),
+		PokerPlayers: []game.PokerPlayer{
+			{Name: "me", Chips: 90, IsPlayingHand: true},
+			{Name: "them", Chips: 90, IsPlayingHand: true},
+		},
+	}
+	deal(&g.PokerPlayers[0], hole, g.CommunityCards)
+	return g
+}
+
+// deal gives p hole and the rank of its best hand on board.
+func deal(p *game.PokerPlayer, hole string, board []poker.Card) {
+	p.HoleCards = cards.MustParse(hole)
+	p.HandRankInt = int(poker.Evaluate(append(append([]poker.Card(nil), p.HoleCards...), board...)))
+}
+
+// decide returns the action an unmixed service takes in g.
+func decide(t *testing.T, g game.Game) game.Action {
+	t.Helper()
+	b := unmixed()
+	b.decisions.Discard()
+	got, err := b.Action(context.Background(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestContinuationBet(t *testing.T) {
+	tests := []struct {
+		name string
+		g    game.Game
+		want game.Action
+	}{
+		{"dry flop after raising", headsUpFlop("5h4d", "Kd7c2h", "me"), game.Action{SelectedAction: "raise", Value: 7}},
+		{"wet flop with nothing", headsUpFlop("3c2d", "JhTh8c", "me"), game.Action{SelectedAction: "check"}},
+		{"dry flop after calling", headsUpFlop("5h4d", "Kd7c2h", "them"), game.Action{SelectedAction: "check"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decide(t, tt.g); got != tt.want {
+				t.Errorf("Action() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlopMadeHand(t *testing.T) {
+	tests := []struct {
+		name string
+		g    game.Game
+		want string
+	}{
+		{"top pair", headsUpFlop("AsKd", "Kc7h2s", "them"), "raise"},
+		{"pair on the board", headsUpFlop("AsQd", "Kc7h7s", "them"), "check"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decide(t, tt.g); got.SelectedAction != tt.want {
+				t.Errorf("Action() = %+v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBigLeadRaise(t *testing.T) {
+	// Only the big lead makes this call a raise, which the pot still sizes
+	params := DefaultBetParams()
+	params.Bluff, params.Slowplay, params.SizeMix = 0, 0, 0
+	params.AllInStrength, params.TurnAggression, params.SemiBluffOuts = 1, 1, 25
+	var me game.PokerPlayer
+	board := cards.MustParse("Kc7h2s9d")
+	deal(&me, "7d2d", board)
+	spot := sizing.Spot{Pot: 40, CurrentBet: 10, Chips: 90, LastRaise: 10, BigBlind: 2}
+	m := Bet(params, me.HoleCards, me.HandRankInt, 90, 0, 10, board,
+		Situation{PotSize: 40, BigBlind: 2, Opponents: 1, LastRaise: 10}, log.New(ioutil.Discard, "", 0))
+	if len(m) != 1 || m[0].Bet <= spot.MinRaise() {
+		t.Errorf("Bet() = %v, want a raise above the minimum %d", m, spot.MinRaise())
+	}
+}
+
+func TestNewSituation(t *testing.T) {
+	players := []game.PokerPlayer{
+		{Name: "me", Chips: 70, IsPlayingHand: true},
+		{Name: "them", Chips: 70, IsPlayingHand: true},
+		{Name: "gone", Chips: 98, IsPlayingHand: false},
+	}
+	situation := func(preflop ...string) Situation {
+		log := append([]string{"-- hand 1 button me", "me small_blind 1", "them big_blind 2"}, preflop...)
+		g := game.Game{PotSize: 62, BigBlind: 2, PokerPlayers: players, HandLog: append(log, "-- flop Kd7c2h")}
+		return NewSituation(g, players[0])
+	}
+	raised, called := situation("me raise 10", "them call 10"), situation("them raise 10", "me call 10")
+	threeBet, weThreeBet := situation("me raise 10", "them raise 30", "me call 30"), situation("them raise 10", "me raise 30", "them call 30")
+	for _, tt := range []struct {
+		name      string
+		s         Situation
+		aggressor bool
+	}{
+		{"we raised", raised, true},
+		{"they raised", called, false},
+		{"they 3-bet", threeBet, false},
+		{"we 3-bet", weThreeBet, true},
+	} {
+		if tt.s.Aggressor != tt.aggressor || tt.s.Range == nil || tt.s.Opponents != 1 {
+			t.Errorf("%s: NewSituation() = aggressor %v, range %v, %d opponents; want %v, a range, 1",
+				tt.name, tt.s.Aggressor, tt.s.Range != nil, tt.s.Opponents, tt.aggressor)
+		}
+	}
+	if threeBet.Range.Combos() >= raised.Range.Combos() {
+		t.Errorf("range after a 3-bet holds %.1f combos, after a call %.1f: want it narrower",
+			threeBet.Range.Combos(), raised.Range.Combos())
+	}
+	if raised.LastRaise != 0 {
+		t.Errorf("LastRaise = %d on a flop nobody bet, want 0", raised.LastRaise)
+	}
+	g := game.Game{BigBlind: 2, PokerPlayers: players, HandLog: []string{"-- hand 1 button me", "me small_blind 1", "them big_blind 2", "me raise 6", "them raise 16"}}
+	if got := NewSituation(g, players[0]).LastRaise; got != 10 {
+		t.Errorf("LastRaise = %d after a raise from 6 to 16, want 10", got)
+	}
+}
+
+// after puts n earlier hands before g's, the lines of each but its header
+// given by hand.
+func after(g game.Game, n int, hand ...string) game.Game {
+	var earlier []string
+	for i := 1; i <= n; i++ {
+		earlier = append(append(earlier, "-- hand "+strconv.Itoa(i)+" button me"), hand...)
+	}
+	g.HandLog[0] = "-- hand " + strconv.Itoa(n+1) + " button btn"
+	g.HandLog = append(earlier, g.HandLog...)
+	return g
+}
+
+// bluffer puts five earlier hands before g's in which "them" bluffed the
+// river and showed.
+func bluffer(g game.Game) game.Game {
+	return after(g, 5, "me small_blind 1", "them big_blind 2", "me call 2", "them check",
+		"-- flop Ac 8h 3s", "them check", "me check", "-- turn Td", "them check", "me check",
+		"-- river 8c", "them raise 10", "me call 10", "them shows 6s 5s", "me shows Qd Jd", "me wins 24")
+}
+
+// loose puts ten earlier hands before g's in which "them" called a raise
+// out of the big blind and folded the flop.
+func loose(g game.Game) game.Game {
+	return after(g, 10, "me small_blind 1", "them big_blind 2", "me raise 6", "them call 6",
+		"-- flop Ac 8h 3s", "them check", "me raise 6", "them fold", "me wins 18")
+}
+
+func TestRiverCheckRaise(t *testing.T) {
+	river := func(me game.PokerPlayer, currentBet, pot int, actions ...string) game.Game {
+		log := []string{
+			"-- hand 1 button btn", "sb small_blind 1", "bb big_blind 2",
+			"them raise 6", "mp fold", "me call 6", "btn fold", "sb fold", "bb fold",
+			"-- flop Kc 7h 2s", "them check", "me raise 8", "them call 8",
+			"-- turn 9d", "them check", "me check",
+			"-- river 4c",
+		}
+		me.Name, me.IsPlayingHand = "me", true
+		g := game.Game{
+			AvailableActions: []string{"fold", "call", "raise"},
+			HandLog:          append(log, actions...),
+			PotSize:          pot,
+			CurrentBet:       currentBet,
+			BigBlind:         2,
+			PokerPlayers: []game.PokerPlayer{
+				{Name: "btn", Chips: 100}, {Name: "sb", Chips: 99}, {Name: "bb", Chips: 98},
+				{Name: "them", Chips: 26, IsPlayingHand: true}, {Name: "mp", Chips: 100}, me,
+			},
+		}
+		g.CommunityCards = cards.MustParse("Kc7h2s9d4c")
+		deal(&g.PokerPlayers[5], "KsQd", g.CommunityCards)
+		return g
+	}
+	tests := []struct {
+		name string
+		g    game.Game
+		want string
+	}{
+		{"lead from early position", river(game.PokerPlayer{Chips: 86}, 20, 51, "them raise 20"), "raise"},
+		{"check-raise from early position", river(game.PokerPlayer{Chips: 66, ChipsCommittedThisAction: 20}, 60, 111,
+			"them check", "me raise 20", "them raise 60"), "fold"},
+		{"check-raise from a shown bluffer", bluffer(river(game.PokerPlayer{Chips: 66, ChipsCommittedThisAction: 20}, 60, 111,
+			"them check", "me raise 20", "them raise 60")), "call"},
+		{"check-raise from a loose early position player", loose(river(game.PokerPlayer{Chips: 66, ChipsCommittedThisAction: 20}, 60, 111,
+			"them check", "me raise 20", "them raise 60")), "call"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decide(t, tt.g); got.SelectedAction != tt.want {
+				t.Errorf("Action() = %+v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExploit(t *testing.T) {
+	defaults := DefaultBetParams()
+	tests := []struct {
+		name  string
+		stats opponent.Stats
+		raise bool
+		check func(p BetParams) bool
+		why   int
+	}{
+		{"too few hands", opponent.Stats{CbetsFaced: 4, CbetFolds: 4, BetsFaced: 4, Calls: 4}, true,
+			func(p BetParams) bool { return p == defaults }, 0},
+		{"folds to c-bets", opponent.Stats{CbetsFaced: 60, CbetFolds: 48}, false,
+			func(p BetParams) bool { return p.CbetWetStrength < defaults.CbetWetStrength && p.SemiBluffOuts < defaults.SemiBluffOuts }, 1},
+		{"never folds to c-bets", opponent.Stats{CbetsFaced: 60}, false,
+			func(p BetParams) bool { return p.CbetWetStrength > defaults.CbetWetStrength }, 1},
+		{"calling station", opponent.Stats{BetsFaced: 100, Calls: 90}, false,
+			func(p BetParams) bool { return p.RiverAggression < defaults.RiverAggression }, 1},
+		{"passive raiser", opponent.Stats{BetsFaced: 100, Calls: 80, Raises: 2}, true,
+			func(p BetParams) bool { return p.RangeFold > defaults.RangeFold && p.CallRankCutoff < defaults.CallRankCutoff }, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, why := Exploit(defaults, tt.stats, Situation{OpponentRaised: tt.raise})
+			if !tt.check(got) || len(why) != tt.why {
+				t.Errorf("Exploit() = %+v, %q", got, why)
+			}
+		})
+	}
+
+	off := defaults
+	off.Exploit = 0
+	if got, why := Exploit(off, opponent.Stats{CbetsFaced: 60, CbetFolds: 60}, Situation{}); got != off || why != nil {
+		t.Errorf("Exploit() with exploit 0 = %+v, %q", got, why)
+	}
+	wild := defaults
+	wild.Exploit = 2
+	got, _ := Exploit(wild, opponent.Stats{CbetsFaced: 1000, CbetFolds: 1000}, Situation{})
+	for _, spec := range BetParamSpecs {
+		if v := *spec.Field(&got); v < spec.Min || v > spec.Max {
+			t.Errorf("%s = %v, outside [%v, %v]", spec.Name, v, spec.Min, spec.Max)
+		}
+	}
+}
+
+// unmixed returns a service that always plays Bet's most likely action, for
+// tests of the rules behind it.
+func unmixed() *basicBotnaughtService {
+	p := DefaultBetParams()
+	p.Bluff, p.Slowplay, p.SizeMix = 0, 0, 0
+	b := &basicBotnaughtService{}
+	if _, err := b.params.Store(p); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestMix(t *testing.T) {
+	logger := log.New(ioutil.Discard, "", 0)
+	flop := func(hole string) Mix {
+		me := headsUpFlop(hole, "Kd7c2h", "them").PokerPlayers[0]
+		return Bet(DefaultBetParams(), me.HoleCards, me.HandRankInt, 90, 0, 0, cards.MustParse("Kd7c2h"), Situation{PotSize: 20, BigBlind: 2, Opponents: 1}, logger)
+	}
+	p := func(m Mix, bet func(int) bool) float64 {
+		total := 0.0
+		for _, c := range m {
+			if bet(c.Bet) {
+				total += c.P
+			}
+		}
+		return total
+	}
+	raise := func(b int) bool { return b > 0 }
+	check := func(b int) bool { return b == 0 }
+
+	bluffs := flop("5h4d")
+	if got := p(bluffs, raise); math.Abs(got-DefaultBetParams().Bluff) > 1e-9 {
+		t.Errorf("checked to with nothing: bluffs %v of the time, want %v (%v)", got, DefaultBetParams().Bluff, bluffs)
+	}
+	slowplays := flop("KhKs")
+	if got := p(slowplays, check); math.Abs(got-DefaultBetParams().Slowplay) > 1e-9 {
+		t.Errorf("flopped a set: slowplays %v of the time, want %v (%v)", got, DefaultBetParams().Slowplay, slowplays)
+	}
+	if got := p(slowplays, func(int) bool { return true }); math.Abs(got-1) > 1e-9 {
+		t.Errorf("probabilities sum to %v (%v)", got, slowplays)
+	}
+
+	// The same seeds make the same decisions, each game's spot sampled afresh
+	// with the cache as the service configures it by default
+	g := headsUpFlop("5h4d", "Kd7c2h", "them")
+	play := func(seed int64) []game.Action {
+		b := &basicBotnaughtService{}
+		b.decisions.Discard()
+		b.cache.Configure(1024, 30*time.Second, nil)
+		b.Seeds().Reset(seed)
+		var actions []game.Action
+		for i := 0; i < 50; i++ {
+			g.GameID = strconv.Itoa(i)
+			a, err := b.Action(context.Background(), g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actions = append(actions, a)
+		}
+		return actions
+	}
+	first := play(7)
+	if again := play(7); !reflect.DeepEqual(first, again) {
+		t.Errorf("seed 7 played %v, then %v", first, again)
+	}
+	seen := map[string]bool{}
+	for _, a := range first {
+		seen[a.SelectedAction] = true
+	}
+	if !seen["check"] || !seen["raise"] {
+		t.Errorf("50 decisions took only %v", seen)
+	}
+
+	counts := map[int]int{}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		counts[Mix{{-1, .2}, {0, .8}}.Sample(rnd)]++
+	}
+	if counts[-1] < 1800 || counts[-1] > 2200 {
+		t.Errorf("folded %d of 10000 times, want about 2000", counts[-1])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		names []string
+		ok    bool
+	}{
+		{[]string{"Bot", "Other"}, true},
+		{[]string{"Bot", "Bot"}, false},
+		{[]string{"Bot", "bot"}, false},
+		{[]string{"a/b"}, false},
+	}
+	for _, tt := range tests {
+		var defs []BotDefinition
+		for _, name := range tt.names {
+			defs = append(defs, BotDefinition{Name: name, Strategy: "basic"})
+		}
+		if err := Validate(defs); (err == nil) != tt.ok {
+			t.Errorf("Validate(%q) = %v, want ok %v", tt.names, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	handlog "go-poker-project/Botnaught/botnaught/pkg/handlog"
+	narrow "go-poker-project/Botnaught/botnaught/pkg/narrow"
+	opponent "go-poker-project/Botnaught/botnaught/pkg/opponent"
+	ranges "go-poker-project/Botnaught/botnaught/pkg/ranges"
+	showdown "go-poker-project/Botnaught/botnaught/pkg/showdown"
+)
+
+// Situation is what Bet knows about a decision besides our cards and chips.
+type Situation struct {
+	PotSize  int
+	BigBlind int
+	// Aggressor is set when we made the last raise before the flop, so that
+	// a bet on the flop is a continuation bet.
+	Aggressor bool
+	// Opponents is how many other players are still in the hand.
+	Opponents int
+	// Range is the hands Bet puts its opponent on, narrowed from their
+	// actions this hand: the last opponent to raise, or the only one left.
+	// It is nil for any two cards.
+	Range *ranges.Range
+	// Opponent names the player Range belongs to, Showdowns is what they
+	// have shown down in earlier hands and Tendencies how they played them.
+	Opponent   string
+	Showdowns  showdown.Stats
+	Tendencies opponent.Stats
+	// OpponentRaised is set when Opponent raised on this street, and
+	// OpponentReraised when they did so over a bet or raise of ours.
+	OpponentRaised   bool
+	OpponentReraised bool
+	// LastRaise is by how much this street's last raise raised, the big
+	// blind counting as the first before the flop.
+	LastRaise int
+}
+
+// NewSituation reads me's situation from g.
+func NewSituation(g game.Game, me game.PokerPlayer) Situation {
+	s := Situation{PotSize: g.PotSize, BigBlind: g.BigBlind}
+	for _, p := range g.PokerPlayers {
+		if p.IsPlayingHand && p.Name != me.Name {
+			s.Opponents++
+		}
+	}
+	raiser, lastRaiser := "", ""
+	preflop := true
+	raisedThisStreet, reraisedThisStreet := map[string]bool{}, map[string]bool{}
+	weBet := false // whether we bet or raised on this street
+	for _, l := range handlog.Current(g.HandLog) {
+		if l.Kind == handlog.StreetLine {
+			preflop = false
+			raisedThisStreet, reraisedThisStreet = map[string]bool{}, map[string]bool{}
+			weBet = false
+		}
+		if l.Kind == handlog.BetLine && l.Verb == handlog.Raise {
+			if preflop {
+				raiser = l.Player
+			}
+			if l.Player != me.Name {
+				lastRaiser = l.Player
+				reraisedThisStreet[l.Player] = weBet
+			} else {
+				weBet = true
+			}
+			raisedThisStreet[l.Player] = true
+		}
+	}
+	s.Aggressor = raiser != "" && raiser == me.Name
+	s.LastRaise = lastRaise(g.HandLog)
+
+	seats := make([]string, len(g.PokerPlayers))
+	for i, p := range g.PokerPlayers {
+		seats[i] = p.Name
+	}
+	held := narrow.Hand(g.HandLog, seats, me.Name, me.HoleCards, g.CommunityCards)
+	for _, p := range g.PokerPlayers {
+		if r, ok := held[p.Name]; ok && p.IsPlayingHand && (p.Name == lastRaiser || s.Opponents == 1) {
+			s.Range, s.Opponent = r, p.Name
+		}
+	}
+	s.OpponentRaised = raisedThisStreet[s.Opponent]
+	s.OpponentReraised = reraisedThisStreet[s.Opponent]
+	return s
+}
+
+// lastRaise returns by how much the last raise on the current street of log
+// raised, the big blind counting as the first before the flop.
+func lastRaise(log []string) int {
+	last, streetBet := 0, 0
+	for _, l := range handlog.Current(log) {
+		if l.Kind == handlog.StreetLine {
+			last, streetBet = 0, 0
+		}
+		if l.Kind == handlog.BetLine && (l.Verb == handlog.Raise || l.Verb == handlog.BigBlind) && l.Amount > streetBet {
+			last, streetBet = l.Amount-streetBet, l.Amount
+		}
+	}
+	return last
+}
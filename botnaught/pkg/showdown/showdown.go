@@ -0,0 +1,249 @@
+// Package showdown harvests the hole cards opponents reveal at showdown. Each
+// revealed hand is linked to what its player did with it, street by street,
+// so that over time we learn what each opponent shows down with and how
+// often their river bets are bluffs.
+package showdown
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	handlog "go-poker-project/Botnaught/botnaught/pkg/handlog"
+	narrow "go-poker-project/Botnaught/botnaught/pkg/narrow"
+	ranges "go-poker-project/Botnaught/botnaught/pkg/ranges"
+)
+
+// BluffStrength is the river strength below which a bet or raise counts as a
+// bluff: the hand beats fewer than half of all hands on the board.
+const BluffStrength = .5
+
+// MaxRecords is how many of a player's latest records are kept in memory.
+const MaxRecords = 100
+
+// Action is one betting action of a shown-down hand.
+type Action struct {
+	Street string `json:"street"`
+	Verb   string `json:"verb"`
+	Amount int    `json:"amount,omitempty"`
+	// Strength is the share of hands the player's hand beat at the time.
+	Strength float64 `json:"strength"`
+}
+
+// Record is one hand a player showed down.
+type Record struct {
+	GameID  string   `json:"game_id"`
+	Hand    int      `json:"hand"`
+	Player  string   `json:"player"`
+	Hole    string   `json:"hole"`
+	Board   string   `json:"board"`
+	Actions []Action `json:"actions"`
+	// RiverBet is set when the player bet or raised the river, and Bluff
+	// when they did so with less than BluffStrength.
+	RiverBet bool `json:"river_bet,omitempty"`
+	Bluff    bool `json:"bluff,omitempty"`
+	Won      bool `json:"won,omitempty"`
+}
+
+// Stats sums up a player's showdowns.
+type Stats struct {
+	Showdowns int `json:"showdowns"`
+	Wins      int `json:"wins"`
+	RiverBets int `json:"river_bets"`
+	Bluffs    int `json:"bluffs"`
+}
+
+// BluffFrequency is the share of shown-down river bets that were bluffs, 0
+// when there were none.
+func (s Stats) BluffFrequency() float64 {
+	if s.RiverBets == 0 {
+		return 0
+	}
+	return float64(s.Bluffs) / float64(s.RiverBets)
+}
+
+// Collector keeps the records and stats of every player seen at showdown.
+// With a path it appends each new record to that file as a line of JSON
+// and can reload them; the zero value keeps them in memory only.
+type Collector struct {
+	mu      sync.Mutex
+	path    string
+	seen    handlog.Seen
+	stats   map[string]Stats
+	records map[string][]Record
+	added   int64
+}
+
+// Open makes c store its records at path, loading the records already
+// there. Hands up to the last one stored of each game count as observed, so
+// that observing their logs again after a restart adds nothing. Lines that
+// aren't records, such as the last one of a crash mid-append, are left out
+// and returned in skipped for the caller to warn of. A missing file is an
+// empty one.
+func (c *Collector) Open(path string) (skipped []error, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.path = path
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			skipped = append(skipped, fmt.Errorf("%s:%d: %v", path, n, err))
+			continue
+		}
+		c.add(r)
+		c.seen.Mark(r.GameID, r.Hand)
+	}
+	return skipped, scanner.Err()
+}
+
+// Path returns the file records are stored in, or "" for none.
+func (c *Collector) Path() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.path
+}
+
+// Observe records every hand of log, from the game gameID, that ended in a
+// showdown and hasn't been observed before, and returns the new records.
+// Hands still being played are left for a later call.
+func (c *Collector) Observe(gameID string, log []string) ([]Record, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var found []Record
+	for _, hand := range c.seen.Finished(gameID, log) {
+		for _, r := range Harvest(hand) {
+			r.GameID = gameID
+			c.add(r)
+			found = append(found, r)
+		}
+	}
+	if len(found) == 0 || c.path == "" {
+		return found, nil
+	}
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return found, err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, r := range found {
+		if err := enc.Encode(r); err != nil {
+			return found, err
+		}
+	}
+	return found, nil
+}
+
+func (c *Collector) add(r Record) {
+	if c.stats == nil {
+		c.stats, c.records = map[string]Stats{}, map[string][]Record{}
+	}
+	s := c.stats[r.Player]
+	s.Showdowns++
+	if r.Won {
+		s.Wins++
+	}
+	if r.RiverBet {
+		s.RiverBets++
+	}
+	if r.Bluff {
+		s.Bluffs++
+	}
+	c.stats[r.Player] = s
+	c.added++
+	records := append(c.records[r.Player], r)
+	if len(records) > MaxRecords {
+		records = records[len(records)-MaxRecords:]
+	}
+	c.records[r.Player] = records
+}
+
+// Stats returns player's stats.
+func (c *Collector) Stats(player string) Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats[player]
+}
+
+// Version returns a number that changes whenever the stats do: the records
+// added so far.
+func (c *Collector) Version() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.added
+}
+
+// All returns the stats of every player seen at showdown.
+func (c *Collector) All() map[string]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	all := make(map[string]Stats, len(c.stats))
+	for name, s := range c.stats {
+		all[name] = s
+	}
+	return all
+}
+
+// Records returns player's latest records, oldest first.
+func (c *Collector) Records(player string) []Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Record(nil), c.records[player]...)
+}
+
+// Harvest returns a record for each player who showed their cards in hand,
+// the parsed lines of one hand.
+func Harvest(hand []handlog.Line) []Record {
+	var board []poker.Card
+	won := map[string]bool{}
+	for _, l := range hand {
+		switch l.Kind {
+		case handlog.StreetLine:
+			board = append(board, l.Cards...)
+		case handlog.WinsLine:
+			won[l.Player] = true
+		}
+	}
+
+	var records []Record
+	for _, show := range hand {
+		if show.Kind != handlog.ShowsLine || len(show.Cards) != 2 {
+			continue
+		}
+		hole := show.Cards
+		r := Record{Player: show.Player, Hole: cards.String(hole), Board: cards.String(board), Won: won[show.Player]}
+		if len(hand) > 0 && hand[0].Kind == handlog.HandLine {
+			r.Hand = hand[0].Number
+		}
+		street, strength := "preflop", narrow.ChenPercentile(hole[0], hole[1])
+		var seen []poker.Card
+		for _, l := range hand {
+			switch {
+			case l.Kind == handlog.StreetLine:
+				seen = append(seen, l.Cards...)
+				street, strength = l.Street, narrow.Strengths(seen)[ranges.Combo(hole[0], hole[1])]
+			case l.Kind == handlog.BetLine && l.Player == show.Player:
+				r.Actions = append(r.Actions, Action{Street: street, Verb: l.Verb, Amount: l.Amount, Strength: strength})
+				if street == "river" && l.Verb == handlog.Raise {
+					r.RiverBet = true
+					r.Bluff = r.Bluff || strength < BluffStrength
+				}
+			}
+		}
+		records = append(records, r)
+	}
+	return records
+}
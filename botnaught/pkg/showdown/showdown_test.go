@@ -0,0 +1,119 @@
+package showdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// game is two hands: in the first Guido bluffs the river and shows, in the
+// second Vinnie value-bets the river and Guido mucks.
+var game = strings.Split(`-- hand 1 button Vinnie
+Vinnie small_blind 1
+Guido big_blind 2
+Vinnie call 2
+Guido check
+-- flop Kc 7h 2s
+Guido check
+Vinnie check
+-- turn 9d
+Guido check
+Vinnie check
+-- river 4c
+Guido raise 10
+Vinnie call 10
+Guido shows 6s 5s
+Vinnie shows Kd Qd
+Vinnie wins 24
+-- hand 2 button Guido
+Guido small_blind 1
+Vinnie big_blind 2
+Guido call 2
+Vinnie check
+-- flop Ac 8h 3s
+Vinnie check
+Guido check
+-- turn Td
+Vinnie check
+Guido check
+-- river 8c
+Vinnie raise 4
+Guido call 4
+Vinnie shows 8d 8s
+Vinnie wins 12`, "\n")
+
+func TestCollector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "showdowns.jsonl")
+	var c Collector
+	if _, err := c.Open(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the finished first hand is harvested while the second is played
+	found, err := c.Observe("g1", game[:20])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("Observe() found %d records, want 2", len(found))
+	}
+	guido := found[0]
+	if guido.Player != "Guido" || guido.Hole != "6s 5s" || !guido.RiverBet || !guido.Bluff || guido.Won {
+		t.Errorf("Guido's record = %+v, want a losing river bluff with 6s 5s", guido)
+	}
+	if n := len(guido.Actions); n != 5 || guido.Actions[n-1].Street != "river" || guido.Actions[n-1].Verb != "raise" {
+		t.Errorf("Guido's actions = %+v, want 5 ending with the river raise", guido.Actions)
+	}
+
+	if found, _ := c.Observe("g1", game); len(found) != 1 || found[0].Player != "Vinnie" || found[0].Bluff || !found[0].Won {
+		t.Errorf("second Observe() = %+v, want Vinnie's winning value bet only", found)
+	}
+	if found, _ := c.Observe("g1", game); len(found) != 0 {
+		t.Errorf("observing again found %d records, want none", len(found))
+	}
+	want := Stats{Showdowns: 2, Wins: 2, RiverBets: 1}
+	if got := c.Stats("Vinnie"); got != want {
+		t.Errorf("Vinnie's stats = %+v, want %+v", got, want)
+	}
+	if f := c.Stats("Guido").BluffFrequency(); f != 1 {
+		t.Errorf("Guido's bluff frequency = %v, want 1", f)
+	}
+
+	// The records survive a restart
+	var reloaded Collector
+	if _, err := reloaded.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reloaded.All(), c.All(); len(got) != 2 || got["Guido"] != want["Guido"] || got["Vinnie"] != want["Vinnie"] {
+		t.Errorf("reloaded stats = %+v, want %+v", got, want)
+	}
+	if rs := reloaded.Records("Guido"); len(rs) != 1 || rs[0].GameID != "g1" || rs[0].Hand != 1 {
+		t.Errorf("reloaded Guido records = %+v", rs)
+	}
+	if found, err := reloaded.Observe("g1", game); len(found) != 0 || err != nil {
+		t.Errorf("observing after a restart = %+v, %v; want no records", found, err)
+	}
+	if got := reloaded.Stats("Vinnie"); got != want {
+		t.Errorf("Vinnie's stats after a restart = %+v, want %+v", got, want)
+	}
+	if b, err := os.ReadFile(path); err != nil || strings.Count(string(b), "\n") != 3 {
+		t.Errorf("stored records = %q, %v; want 3 lines", b, err)
+	}
+
+	// A crash mid-append leaves a partial last line, which is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"game_id":"g1","hand":3,"pla`)
+	f.Close()
+	var crashed Collector
+	skipped, err := crashed.Open(path)
+	if err != nil || len(skipped) != 1 {
+		t.Fatalf("Open() after a crash = %v, %v; want one line skipped", skipped, err)
+	}
+	if got := crashed.Stats("Vinnie"); got != want {
+		t.Errorf("Vinnie's stats after a crash = %+v, want %+v", got, want)
+	}
+}
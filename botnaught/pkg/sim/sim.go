@@ -0,0 +1,488 @@
+// Package sim plays complete hands of no-limit hold'em offline. It deals
+// from seeded decks, posts blinds, enforces the betting rules and asks each
+// seat's service.BotnaughtService for its actions in-process with the same
+// game.Game snapshots the game server sends over HTTP.
+//
+// A raise's Value is the total the bot wants to have committed this street
+// ("raise to"), which is how Bet sizes its bets. Raises below the minimum are
+// raised to it and raises beyond the bot's stack are capped there. "check"
+// and "call" both match the current bet; anything else, including an error
+// from the bot, checks if that is free and folds otherwise.
+package sim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	poker "github.com/chehsunliu/poker"
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+	handlog "go-poker-project/Botnaught/botnaught/pkg/handlog"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+// Street is a betting round.
+type Street int
+
+// The streets in the order they are played.
+const (
+	Preflop Street = iota
+	Flop
+	Turn
+	River
+	NumStreets
+)
+
+var streetNames = [NumStreets]string{"preflop", "flop", "turn", "river"}
+
+func (s Street) String() string {
+	return streetNames[s]
+}
+
+// Seat is a player at the table.
+type Seat struct {
+	Name string
+	Bot  service.BotnaughtService
+}
+
+// Hand describes a hand to play. Seats, Stacks and the deal are indexed by
+// seat, so replaying a Seed with the seats permuted deals the same cards to
+// the same chairs.
+type Hand struct {
+	GameID        string
+	Number        int
+	Seats         []Seat
+	Stacks        []int
+	Button        int
+	SmallBlind    int
+	BigBlind      int
+	StartingStack int
+	Seed          int64
+	// Log holds earlier lines of the game's hand log; the bots see them
+	// ahead of this hand's own.
+	Log []string
+}
+
+// HistoryHands is how many of a game's latest hands a History keeps: enough
+// for every bot to see each finished hand in a decision of its own, while
+// the log the bots are sent doesn't grow with the game.
+const HistoryHands = 10
+
+// History collects the logs of a game's hands to set as the next Hand's Log,
+// so that bots keeping track of their opponents see the hands before. The
+// zero value is empty.
+type History struct {
+	hands [][]string
+}
+
+// Add appends the log of a hand just played.
+func (h *History) Add(log []string) {
+	h.hands = append(h.hands, log)
+	if len(h.hands) > HistoryHands {
+		h.hands = h.hands[len(h.hands)-HistoryHands:]
+	}
+}
+
+// Log returns the lines of the hands kept, oldest first.
+func (h *History) Log() []string {
+	var log []string
+	for _, hand := range h.hands {
+		log = append(log, hand...)
+	}
+	return log
+}
+
+// Action is one decision made during the hand.
+type Action struct {
+	Seat   int
+	Street Street
+	Verb   string
+	// To is the seat's commitment for the street after acting and Added the
+	// chips the action put in.
+	To    int
+	Added int
+}
+
+// Result is what happened in a hand.
+type Result struct {
+	Hole    [][]poker.Card
+	Board   []poker.Card
+	Actions []Action
+	// Invested holds the chips each seat put in on each street, blinds
+	// included, and Won the chips each seat took from the pot.
+	Invested [][NumStreets]int
+	Won      []int
+	// Deltas holds each seat's profit: Won less everything it invested.
+	Deltas []int
+	// EndStreet is the last street reached; Showdown is false when everyone
+	// but one player folded.
+	EndStreet Street
+	Showdown  bool
+	Log       []string
+	// Errors holds the errors bots returned; their actions were played as
+	// checks or folds. SeatErrors counts those of each seat.
+	Errors     []error
+	SeatErrors []int
+}
+
+// Play plays h to the end.
+func Play(ctx context.Context, h Hand) (Result, error) {
+	n := len(h.Seats)
+	switch {
+	case n < 2:
+		return Result{}, errors.New("sim: need at least two seats")
+	case len(h.Stacks) != n:
+		return Result{}, fmt.Errorf("sim: %d stacks for %d seats", len(h.Stacks), n)
+	case n*2+5 > 52:
+		return Result{}, fmt.Errorf("sim: too many seats: %d", n)
+	case h.SmallBlind <= 0 || h.BigBlind < h.SmallBlind:
+		return Result{}, fmt.Errorf("sim: bad blinds %d/%d", h.SmallBlind, h.BigBlind)
+	case h.Button < 0 || h.Button >= n:
+		return Result{}, fmt.Errorf("sim: button %d outside %d seats", h.Button, n)
+	}
+	for i, stack := range h.Stacks {
+		if stack <= 0 {
+			return Result{}, fmt.Errorf("sim: seat %d has no chips", i)
+		}
+	}
+
+	t := &table{
+		h:         h,
+		deck:      cards.NewDeck(h.Seed),
+		stacks:    append([]int(nil), h.Stacks...),
+		committed: make([]int, n),
+		folded:    make([]bool, n),
+		allIn:     make([]bool, n),
+		res: Result{
+			Hole:       make([][]poker.Card, n),
+			Invested:   make([][NumStreets]int, n),
+			Won:        make([]int, n),
+			Deltas:     make([]int, n),
+			SeatErrors: make([]int, n),
+		},
+	}
+	for i := range h.Seats {
+		t.res.Hole[i] = t.deck.Draw(2)
+	}
+	t.log(handlog.Hand(h.Number, h.Seats[h.Button].Name))
+
+	sb, bb := (h.Button+1)%n, (h.Button+2)%n
+	if n == 2 {
+		sb, bb = h.Button, (h.Button+1)%n
+	}
+	t.put(sb, h.SmallBlind)
+	t.log(handlog.Bet(h.Seats[sb].Name, handlog.SmallBlind, t.committed[sb]))
+	t.put(bb, h.BigBlind)
+	t.log(handlog.Bet(h.Seats[bb].Name, handlog.BigBlind, t.committed[bb]))
+	t.currentBet, t.lastRaise = h.BigBlind, h.BigBlind
+	if t.committed[sb] > t.currentBet {
+		t.currentBet = t.committed[sb]
+	}
+
+	for t.street = Preflop; t.street < NumStreets; t.street++ {
+		first := (bb + 1) % n
+		if t.street > Preflop {
+			t.deal()
+			first = (h.Button + 1) % n
+		}
+		t.res.EndStreet = t.street
+		t.bettingRound(ctx, first)
+		if t.inHand() == 1 {
+			break
+		}
+	}
+	t.award()
+	return t.res, nil
+}
+
+// table is the state of a hand in progress.
+type table struct {
+	h      Hand
+	deck   *cards.Deck
+	street Street
+	stacks []int
+	// committed holds each seat's chips in on the current street.
+	committed  []int
+	folded     []bool
+	allIn      []bool
+	currentBet int
+	lastRaise  int
+	// acted marks the seats that have acted on the street since the last
+	// full raise; an all-in short of one doesn't let them raise again.
+	acted []bool
+	res   Result
+}
+
+func (t *table) log(line string) {
+	t.res.Log = append(t.res.Log, line)
+}
+
+// put moves up to amount chips from seat's stack into the pot.
+func (t *table) put(seat, amount int) int {
+	if amount >= t.stacks[seat] {
+		amount = t.stacks[seat]
+		t.allIn[seat] = true
+	}
+	t.stacks[seat] -= amount
+	t.committed[seat] += amount
+	t.res.Invested[seat][t.street] += amount
+	return amount
+}
+
+// deal starts a new street.
+func (t *table) deal() {
+	count := 1
+	if t.street == Flop {
+		count = 3
+	}
+	dealt := t.deck.Draw(count)
+	t.res.Board = append(t.res.Board, dealt...)
+	t.log(handlog.Street(t.street.String(), dealt))
+	for i := range t.committed {
+		t.committed[i] = 0
+	}
+	t.currentBet, t.lastRaise = 0, t.h.BigBlind
+}
+
+// inHand counts the seats that haven't folded.
+func (t *table) inHand() int {
+	count := 0
+	for _, folded := range t.folded {
+		if !folded {
+			count++
+		}
+	}
+	return count
+}
+
+// bettingRound asks the seats to act, starting with first, until everyone
+// still able to bet has matched the current bet.
+func (t *table) bettingRound(ctx context.Context, first int) {
+	n := len(t.h.Seats)
+	pending := make([]bool, n)
+	t.acted = make([]bool, n)
+	canAct := 0
+	for i := range pending {
+		pending[i] = !t.folded[i] && !t.allIn[i]
+		if pending[i] {
+			canAct++
+		}
+	}
+	// A lone player who can still bet has no one to bet against, unless
+	// they have a bet to call.
+	for i := range pending {
+		if canAct == 1 && pending[i] && t.committed[i] >= t.currentBet {
+			pending[i] = false
+		}
+	}
+
+	for i, left := first, true; left && t.inHand() > 1; i = (i + 1) % n {
+		if pending[i] {
+			pending[i] = false
+			full := t.act(ctx, i)
+			// A full raise reopens the betting; a short all-in only has to be called
+			for j := range pending {
+				if j != i && !t.folded[j] && !t.allIn[j] && (full || t.committed[j] < t.currentBet) {
+					pending[j] = true
+				}
+			}
+		}
+		left = false
+		for _, p := range pending {
+			left = left || p
+		}
+	}
+}
+
+// act asks seat for its action and applies it, reporting whether it made a
+// full raise: one by at least the last raise.
+func (t *table) act(ctx context.Context, seat int) (raised bool) {
+	name := t.h.Seats[seat].Name
+	toCall := t.currentBet - t.committed[seat]
+	action, err := t.h.Seats[seat].Bot.Action(ctx, t.snapshot(seat))
+	if err != nil {
+		t.res.Errors = append(t.res.Errors, fmt.Errorf("%s: %v", name, err))
+		t.res.SeatErrors[seat]++
+		action = game.Action{}
+	}
+
+	verb := action.SelectedAction
+	defer func() { t.acted[seat] = true }()
+	switch {
+	case verb == handlog.Raise && action.Value > t.currentBet && t.stacks[seat] > toCall && !t.acted[seat]:
+		to := action.Value
+		if min := t.currentBet + t.lastRaise; to < min {
+			to = min
+		}
+		if max := t.committed[seat] + t.stacks[seat]; to > max {
+			to = max
+		}
+		t.record(seat, handlog.Raise, t.put(seat, to-t.committed[seat]))
+		full := t.committed[seat]-t.currentBet >= t.lastRaise
+		if full {
+			t.lastRaise = t.committed[seat] - t.currentBet
+			for i := range t.acted {
+				t.acted[i] = false
+			}
+		}
+		t.currentBet = t.committed[seat]
+		return full
+	case toCall == 0 && verb != handlog.Fold:
+		t.record(seat, handlog.Check, 0)
+	case verb == handlog.Check || verb == handlog.Call || verb == handlog.Raise:
+		t.record(seat, handlog.Call, t.put(seat, toCall))
+	default:
+		t.folded[seat] = true
+		t.record(seat, handlog.Fold, 0)
+	}
+	return false
+}
+
+func (t *table) record(seat int, verb string, added int) {
+	t.res.Actions = append(t.res.Actions, Action{Seat: seat, Street: t.street, Verb: verb, To: t.committed[seat], Added: added})
+	t.log(handlog.Bet(t.h.Seats[seat].Name, verb, t.committed[seat]))
+}
+
+// snapshot is the game.Game seat sees when asked to act: only its own hole
+// cards and hand rank are shown.
+func (t *table) snapshot(seat int) game.Game {
+	g := game.Game{
+		GameID:         t.h.GameID,
+		HandLog:        append(append([]string(nil), t.h.Log...), t.res.Log...),
+		CommunityCards: append([]poker.Card(nil), t.res.Board...),
+		CurrentBet:     t.currentBet,
+		SmallBlind:     t.h.SmallBlind,
+		BigBlind:       t.h.BigBlind,
+		StartingStack:  t.h.StartingStack,
+	}
+	for i, s := range t.h.Seats {
+		p := game.PokerPlayer{
+			Name:                     s.Name,
+			Chips:                    t.stacks[i],
+			HandRankInt:              -1,
+			ChipsCommittedThisAction: t.committed[i],
+			IsPlayingHand:            !t.folded[i],
+		}
+		if i == seat {
+			p.HoleCards = append([]poker.Card(nil), t.res.Hole[i]...)
+			p.HandRankInt = 0
+			if len(t.res.Board) >= 3 {
+				p.HandRankInt = int(poker.Evaluate(append(append([]poker.Card(nil), p.HoleCards...), t.res.Board...)))
+			}
+		}
+		for _, invested := range t.res.Invested[i] {
+			g.PotSize += invested
+		}
+		g.PokerPlayers = append(g.PokerPlayers, p)
+	}
+
+	toCall := t.currentBet - t.committed[seat]
+	g.AvailableActions = []string{handlog.Fold, handlog.Check, handlog.Raise}
+	if toCall > 0 {
+		g.AvailableActions[1] = handlog.Call
+	}
+	if t.stacks[seat] <= toCall || t.acted[seat] {
+		g.AvailableActions = g.AvailableActions[:2]
+	}
+	return g
+}
+
+// award splits the pot, side pots included, between the best hands still in.
+func (t *table) award() {
+	n := len(t.h.Seats)
+	total := make([]int, n)
+	for i, streets := range t.res.Invested {
+		for _, invested := range streets {
+			total[i] += invested
+		}
+	}
+
+	ranks := make([]int32, n)
+	t.res.Showdown = t.inHand() > 1
+	for i := range ranks {
+		if t.folded[i] {
+			continue
+		}
+		if t.res.Showdown {
+			ranks[i] = poker.Evaluate(append(append([]poker.Card(nil), t.res.Hole[i]...), t.res.Board...))
+			t.log(handlog.Shows(t.h.Seats[i].Name, t.res.Hole[i]))
+		}
+	}
+
+	// Peel the pot off in layers, one per distinct contribution: a player
+	// can only win the layers they paid into.
+	for paid := 0; ; {
+		level := 0
+		for _, c := range total {
+			if c > paid && (level == 0 || c < level) {
+				level = c
+			}
+		}
+		if level == 0 {
+			break
+		}
+		pot := 0
+		var winners []int
+		for i, c := range total {
+			if c > paid {
+				pot += min(c, level) - paid
+			}
+			if t.folded[i] || c < level {
+				continue
+			}
+			switch {
+			case len(winners) == 0 || ranks[i] < ranks[winners[0]]:
+				winners = []int{i}
+			case ranks[i] == ranks[winners[0]]:
+				winners = append(winners, i)
+			}
+		}
+		if len(winners) == 0 {
+			// Everyone who paid this much folded; the chips go to whoever
+			// is left.
+			for i := range t.folded {
+				if !t.folded[i] {
+					winners = []int{i}
+					break
+				}
+			}
+		}
+		t.split(pot, winners)
+		paid = level
+	}
+
+	for i := range t.res.Won {
+		t.res.Deltas[i] = t.res.Won[i] - total[i]
+		if t.res.Won[i] > 0 {
+			t.log(handlog.Wins(t.h.Seats[i].Name, t.res.Won[i]))
+		}
+	}
+}
+
+// split shares pot between winners; odd chips go to the first winners left
+// of the button.
+func (t *table) split(pot int, winners []int) {
+	n := len(t.h.Seats)
+	share, odd := pot/len(winners), pot%len(winners)
+	for i := 1; i <= n; i++ {
+		seat := (t.h.Button + i) % n
+		for _, w := range winners {
+			if w != seat {
+				continue
+			}
+			t.res.Won[seat] += share
+			if odd > 0 {
+				t.res.Won[seat]++
+				odd--
+			}
+		}
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
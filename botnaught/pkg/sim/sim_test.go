@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	game "github.com/gSchool/golang-curriculum-c-6/server/pkg/game"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+// scripted always answers with the same action and remembers what it saw.
+type scripted struct {
+	action game.Action
+	seen   []game.Game
+}
+
+func (s *scripted) Health(ctx context.Context) (service.HealthStatus, error) {
+	return service.HealthStatus{Live: true, Ready: true}, nil
+}
+func (s *scripted) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	s.seen = append(s.seen, g)
+	return s.action, nil
+}
+
+func seats(actions ...game.Action) []Seat {
+	s := make([]Seat, len(actions))
+	for i, a := range actions {
+		s[i] = Seat{Name: string(rune('A' + i)), Bot: &scripted{action: a}}
+	}
+	return s
+}
+
+var (
+	fold  = game.Action{SelectedAction: "fold"}
+	call  = game.Action{SelectedAction: "call"}
+	shove = game.Action{SelectedAction: "raise", Value: 1000}
+)
+
+func sum(xs []int) (total int) {
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}
+
+func TestPlay(t *testing.T) {
+	tests := []struct {
+		name       string
+		seats      []Seat
+		stacks     []int
+		wantDeltas []int
+		showdown   bool
+	}{
+		{"heads-up: button folds the small blind", seats(fold, fold), []int{100, 100}, []int{-1, 1}, false},
+		{"three-handed: blinds win when everyone folds", seats(fold, fold, fold), []int{100, 100, 100}, []int{0, -1, 1}, false},
+		{"all in with side pots", seats(shove, shove, call), []int{10, 50, 80}, nil, true},
+		{"everyone checks down", seats(call, call, call), []int{100, 100, 100}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Hand{GameID: tt.name, Seats: tt.seats, Stacks: tt.stacks, SmallBlind: 1, BigBlind: 2, StartingStack: 100, Seed: 42}
+			res, err := Play(context.Background(), h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sum(res.Deltas) != 0 {
+				t.Errorf("deltas %v don't sum to zero", res.Deltas)
+			}
+			for i, d := range res.Deltas {
+				if -d > tt.stacks[i] {
+					t.Errorf("seat %d lost %d with a stack of %d", i, -d, tt.stacks[i])
+				}
+			}
+			if tt.wantDeltas != nil && !reflect.DeepEqual(res.Deltas, tt.wantDeltas) {
+				t.Errorf("deltas = %v, want %v", res.Deltas, tt.wantDeltas)
+			}
+			if res.Showdown != tt.showdown {
+				t.Errorf("showdown = %v, want %v", res.Showdown, tt.showdown)
+			}
+			if tt.showdown && len(res.Board) != 5 {
+				t.Errorf("showdown with board %v", res.Board)
+			}
+
+			again, _ := Play(context.Background(), h)
+			if !reflect.DeepEqual(res.Deltas, again.Deltas) || !reflect.DeepEqual(res.Log, again.Log) {
+				t.Error("replaying the same seed played a different hand")
+			}
+		})
+	}
+}
+
+func TestPlaySnapshots(t *testing.T) {
+	s := seats(call, call)
+	_, err := Play(context.Background(), Hand{GameID: "snap", Seats: s, Stacks: []int{100, 100}, SmallBlind: 1, BigBlind: 2, StartingStack: 100, Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s[0].Bot.(*scripted).seen[0]
+	if first.CurrentBet != 2 || first.PotSize != 3 || len(first.CommunityCards) != 0 {
+		t.Errorf("button's first snapshot: bet %d pot %d board %v", first.CurrentBet, first.PotSize, first.CommunityCards)
+	}
+	if len(first.PokerPlayers[0].HoleCards) != 2 || len(first.PokerPlayers[1].HoleCards) != 0 {
+		t.Error("snapshot should show the acting seat's hole cards only")
+	}
+	if !reflect.DeepEqual(first.AvailableActions, []string{"fold", "call", "raise"}) {
+		t.Errorf("available actions = %v", first.AvailableActions)
+	}
+	river := s[1].Bot.(*scripted).seen[len(s[1].Bot.(*scripted).seen)-1]
+	if len(river.CommunityCards) != 5 || river.PokerPlayers[1].HandRankInt <= 0 {
+		t.Errorf("big blind's river snapshot: board %v rank %d", river.CommunityCards, river.PokerPlayers[1].HandRankInt)
+	}
+}
+
+// sequence answers with its actions in turn, then calls.
+type sequence struct {
+	scripted
+	actions []game.Action
+}
+
+func (s *sequence) Action(ctx context.Context, g game.Game) (game.Action, error) {
+	s.seen = append(s.seen, g)
+	if len(s.actions) == 0 {
+		return call, nil
+	}
+	a := s.actions[0]
+	s.actions = s.actions[1:]
+	return a, nil
+}
+
+func TestShortAllInDoesNotReopen(t *testing.T) {
+	raiser := &sequence{actions: []game.Action{{SelectedAction: "raise", Value: 10}, {SelectedAction: "raise", Value: 60}}}
+	s := []Seat{{Name: "A", Bot: raiser}, {Name: "B", Bot: &scripted{action: shove}}, {Name: "C", Bot: &scripted{action: call}}}
+	res, err := Play(context.Background(), Hand{GameID: "short", Seats: s, Stacks: []int{100, 15, 100}, SmallBlind: 1, BigBlind: 2, StartingStack: 100, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// B's all-in to 15 raises by 5, short of A's raise by 8: A may call but not raise
+	want := []string{"A raise 10", "B raise 15", "C call 15", "A call 15"}
+	var preflop []string
+	for _, l := range res.Log {
+		if l[0] == '-' && len(preflop) > 0 {
+			break
+		}
+		if l[0] != '-' && !strings.Contains(l, "blind") {
+			preflop = append(preflop, l)
+		}
+	}
+	if !reflect.DeepEqual(preflop, want) {
+		t.Errorf("preflop = %q, want %q", preflop, want)
+	}
+	second := raiser.seen[1]
+	for _, a := range second.AvailableActions {
+		if a == "raise" {
+			t.Errorf("after a short all-in A may %v", second.AvailableActions)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package sizing sizes bets and raises as fractions of the pot rather than of
+// our stack, so that a bet means the same to the other players whether the
+// pot holds 3 chips or 300. Sizes follow the street, the board's texture and
+// the stack-to-pot ratio, and come out as the raise-to amount
+// game.Action.Value takes, within the minimum raise and our chips.
+package sizing
+
+import (
+	"math"
+
+	poker "github.com/chehsunliu/poker"
+	texture "go-poker-project/Botnaught/botnaught/pkg/texture"
+)
+
+// Size is a bet as a share of the pot.
+type Size int
+
+const (
+	Third Size = iota
+	Half
+	ThreeQuarters
+	Pot
+	// Overbet is one and a half times the pot.
+	Overbet
+	// AllIn is every chip we have, whatever the pot.
+	AllIn
+)
+
+var fractions = [...]float64{1.0 / 3, .5, .75, 1, 1.5, math.Inf(1)}
+
+// Fraction returns the share of the pot s bets, +Inf for AllIn.
+func (s Size) Fraction() float64 {
+	return fractions[s]
+}
+
+func (s Size) String() string {
+	return [...]string{"third pot", "half pot", "three-quarter pot", "pot", "overbet", "all in"}[s]
+}
+
+// Smaller returns the next size down, Third being the smallest.
+func (s Size) Smaller() Size {
+	if s == Third {
+		return s
+	}
+	return s - 1
+}
+
+// Larger returns the next size up, AllIn being the largest.
+func (s Size) Larger() Size {
+	if s == AllIn {
+		return s
+	}
+	return s + 1
+}
+
+// Commit is the share of the pot a bet makes below which the chips left
+// behind are not worth keeping: a bet that would leave less goes all in.
+const Commit = .25
+
+// Spot is what sizing needs to know of a decision, in chips.
+type Spot struct {
+	// Pot holds every chip bet so far, this street's included.
+	Pot int
+	// CurrentBet is the street's bet to match, of which we have Committed.
+	CurrentBet int
+	Committed  int
+	// Chips are the chips we have behind.
+	Chips int
+	// LastRaise is by how much the street's last raise raised; a raise
+	// must raise by at least as much, and by at least BigBlind.
+	LastRaise int
+	BigBlind  int
+}
+
+// ToCall returns what calling costs.
+func (s Spot) ToCall() int {
+	if call := s.CurrentBet - s.Committed; call > 0 {
+		return call
+	}
+	return 0
+}
+
+// SPR returns the stack-to-pot ratio after calling: the chips we would
+// have behind over the pot. It is +Inf for an empty pot.
+func (s Spot) SPR() float64 {
+	pot := s.Pot + s.ToCall()
+	if pot == 0 {
+		return math.Inf(1)
+	}
+	return float64(s.Chips-s.ToCall()) / float64(pot)
+}
+
+// MinRaise returns the smallest raise-to allowed.
+func (s Spot) MinRaise() int {
+	by := s.LastRaise
+	if by < s.BigBlind {
+		by = s.BigBlind
+	}
+	return s.CurrentBet + by
+}
+
+// MaxRaise returns the raise-to that puts in all our chips.
+func (s Spot) MaxRaise() int {
+	return s.Committed + s.Chips
+}
+
+// To returns the raise-to that bets fraction of the pot after calling. It
+// is at least the minimum raise, and all in when that is more than we have
+// or when it would leave less than Commit of the pot behind.
+func (s Spot) To(fraction float64) int {
+	max := s.MaxRaise()
+	if math.IsInf(fraction, 1) || s.MinRaise() >= max {
+		return max
+	}
+	pot := s.Pot + s.ToCall()
+	to := s.CurrentBet + int(math.Round(fraction*float64(pot)))
+	if to < s.MinRaise() {
+		to = s.MinRaise()
+	}
+	// The pot once the bet is called
+	after := s.Pot + (to - s.Committed) + (to - s.CurrentBet)
+	if to >= max || float64(max-to) < Commit*float64(after) {
+		return max
+	}
+	return to
+}
+
+// Value returns the raise-to of size.
+func (s Spot) Value(size Size) int {
+	return s.To(size.Fraction())
+}
+
+// Choose returns the size to bet a hand of strength, 0 for the weakest and
+// 1 for the strongest, on board with spr behind:
+//
+//   - Before the flop, raises are pot sized.
+//   - On the flop and turn bets grow with the street, a size larger on wet
+//     boards to charge the draws.
+//   - On the river, where there is nothing left to charge, the strongest
+//     hands overbet and the rest bet three-quarters.
+//   - With less than a pot behind, or the nuts and little behind, we are
+//     committed: all in.
+func Choose(board []poker.Card, strength float64, spr float64) Size {
+	switch {
+	case spr < 1:
+		return AllIn
+	case len(board) < 3:
+		return Pot
+	case strength >= .95 && spr < 3:
+		return AllIn
+	}
+	var size Size
+	switch len(board) {
+	case 3:
+		size = Half
+	case 4:
+		size = ThreeQuarters
+	default:
+		if strength >= .9 {
+			return Overbet
+		}
+		return ThreeQuarters
+	}
+	if texture.Analyze(board).Wet {
+		size = size.Larger()
+	}
+	return size
+}
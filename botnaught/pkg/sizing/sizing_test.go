@@ -0,0 +1,67 @@
+package sizing
+
+import (
+	"math"
+	"testing"
+
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+func TestTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		spot     Spot
+		fraction float64
+		want     int
+	}{
+		{"half pot", Spot{Pot: 20, Chips: 90, BigBlind: 2}, .5, 10},
+		{"third pot", Spot{Pot: 20, Chips: 90, BigBlind: 2}, 1.0 / 3, 7},
+		{"at least the big blind", Spot{Pot: 20, Chips: 90, BigBlind: 2}, 0, 2},
+		{"half pot over a bet", Spot{Pot: 30, CurrentBet: 10, Chips: 90, LastRaise: 10, BigBlind: 2}, .5, 30},
+		{"at least the last raise", Spot{Pot: 30, CurrentBet: 10, Chips: 90, LastRaise: 10, BigBlind: 2}, .1, 20},
+		{"counting what we put in", Spot{Pot: 30, CurrentBet: 10, Committed: 4, Chips: 86, LastRaise: 10, BigBlind: 2}, .5, 28},
+		{"too little left behind", Spot{Pot: 100, Chips: 60, BigBlind: 2}, .5, 60},
+		{"too short to raise", Spot{Pot: 10, CurrentBet: 20, Chips: 15, LastRaise: 10, BigBlind: 2}, .5, 15},
+		{"all in", Spot{Pot: 20, Committed: 5, Chips: 90, BigBlind: 2}, AllIn.Fraction(), 95},
+	}
+	for _, tt := range tests {
+		if got := tt.spot.To(tt.fraction); got != tt.want {
+			t.Errorf("%s: To(%v) = %d, want %d", tt.name, tt.fraction, got, tt.want)
+		}
+	}
+
+	if got := (Spot{Pot: 30, CurrentBet: 10, Chips: 90}).SPR(); got != 2 {
+		t.Errorf("SPR() = %v, want 2", got)
+	}
+	if got := (Spot{Chips: 90}).SPR(); !math.IsInf(got, 1) {
+		t.Errorf("SPR() of an empty pot = %v, want +Inf", got)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	tests := []struct {
+		board    string
+		strength float64
+		spr      float64
+		want     Size
+	}{
+		{"", .5, 50, Pot},
+		{"Kd7c2h", .8, 5, Half},
+		{"JhTh9c", .8, 5, ThreeQuarters},
+		{"Kd7c2h4s", .8, 5, ThreeQuarters},
+		{"JhTh9c2d", .8, 5, Pot},
+		{"Kd7c2h4s9d", .8, 5, ThreeQuarters},
+		{"Kd7c2h4s9d", .92, 5, Overbet},
+		{"Kd7c2h", .97, 2, AllIn},
+		{"Kd7c2h", .5, .8, AllIn},
+	}
+	for _, tt := range tests {
+		if got := Choose(cards.MustParse(tt.board), tt.strength, tt.spr); got != tt.want {
+			t.Errorf("Choose(%q, %v, %v) = %v, want %v", tt.board, tt.strength, tt.spr, got, tt.want)
+		}
+	}
+
+	if Third.Smaller() != Third || AllIn.Larger() != AllIn || Half.Larger() != ThreeQuarters {
+		t.Error("Smaller and Larger don't stop at the ends")
+	}
+}
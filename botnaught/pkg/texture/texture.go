@@ -0,0 +1,194 @@
+// Package texture labels boards: how many suits and ranks they share, how
+// connected and how high they are, and what they make possible. Wet boards
+// offer many draws and reward betting bigger; dry ones few.
+package texture
+
+import (
+	"strings"
+
+	poker "github.com/chehsunliu/poker"
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+// Suitedness is how the board's suits fall.
+type Suitedness int
+
+const (
+	// Rainbow boards have no two cards of a suit.
+	Rainbow Suitedness = iota
+	// TwoTone boards have two or more of a suit, but not every card.
+	TwoTone
+	// Monotone boards are all one suit.
+	Monotone
+)
+
+func (s Suitedness) String() string {
+	return [...]string{"rainbow", "two-tone", "monotone"}[s]
+}
+
+// Texture labels a board.
+type Texture struct {
+	Suits Suitedness
+	// Paired boards have two or more cards of a rank.
+	Paired bool
+	// Connected boards have two cards of adjacent ranks or one rank apart.
+	Connected bool
+	// High boards have two or more cards ten or above; Low boards none above
+	// eight.
+	High bool
+	Low  bool
+	// FlushPossible and StraightPossible say whether a player can already
+	// hold a flush or straight: three cards of a suit, three ranks within
+	// five.
+	FlushPossible    bool
+	StraightPossible bool
+	// Wet boards make a flush or straight possible, or are two-tone and
+	// connected; the others are dry.
+	Wet bool
+}
+
+// Analyze labels a board of three to five cards. Smaller boards have no
+// texture and come back zero.
+func Analyze(board []poker.Card) Texture {
+	var t Texture
+	if len(board) < 3 {
+		return t
+	}
+	var suits [4]int
+	var ranks [13]int
+	most, high, top := 0, 0, 0
+	for _, c := range board {
+		s, r := cards.Suit(c), cards.Rank(c)
+		suits[s]++
+		ranks[r]++
+		if suits[s] > most {
+			most = suits[s]
+		}
+		if ranks[r] > 1 {
+			t.Paired = true
+		}
+		if r >= 8 {
+			high++
+		}
+		if r > top {
+			top = r
+		}
+	}
+	switch {
+	case most == len(board):
+		t.Suits = Monotone
+	case most >= 2:
+		t.Suits = TwoTone
+	}
+	t.FlushPossible = most >= 3
+	t.High = high >= 2
+	t.Low = top <= 6
+
+	mask := cards.RankMask(board)
+	for r := 0; r < 13; r++ {
+		if ranks[r] == 0 {
+			continue
+		}
+		for gap := 1; gap <= 2 && r+gap < 13; gap++ {
+			if ranks[r+gap] > 0 {
+				t.Connected = true
+			}
+		}
+	}
+	// The ace also plays low with the deuce and trey.
+	if ranks[12] > 0 && (ranks[0] > 0 || ranks[1] > 0) {
+		t.Connected = true
+	}
+	for low := uint(0); low <= 9; low++ {
+		if cards.Bits(mask>>low&0x1f) >= 3 {
+			t.StraightPossible = true
+		}
+	}
+	t.Wet = t.FlushPossible || t.StraightPossible || (t.Suits == TwoTone && t.Connected)
+	return t
+}
+
+// String lists the labels, e.g. "wet two-tone connected high, straight
+// possible".
+func (t Texture) String() string {
+	words := []string{"dry"}
+	if t.Wet {
+		words[0] = "wet"
+	}
+	words = append(words, t.Suits.String())
+	for _, l := range []struct {
+		on   bool
+		name string
+	}{{t.Paired, "paired"}, {t.Connected, "connected"}, {t.High, "high"}, {t.Low, "low"}} {
+		if l.on {
+			words = append(words, l.name)
+		}
+	}
+	s := strings.Join(words, " ")
+	if t.FlushPossible {
+		s += ", flush possible"
+	}
+	if t.StraightPossible {
+		s += ", straight possible"
+	}
+	return s
+}
+
+// Change describes what the last card dealt did to the board.
+type Change struct {
+	// FlushPossible and StraightPossible are set when the card made a flush
+	// or straight possible that wasn't before.
+	FlushPossible    bool
+	StraightPossible bool
+	// Paired is set when the card pairs a card before it and Overcard when
+	// it is above all of them.
+	Paired   bool
+	Overcard bool
+}
+
+// Compare describes how the last card of board changed it from the board
+// before it. Boards before the turn have no change.
+func Compare(board []poker.Card) Change {
+	var c Change
+	if len(board) < 4 {
+		return c
+	}
+	prev, last := board[:len(board)-1], board[len(board)-1]
+	before, after := Analyze(prev), Analyze(board)
+	c.FlushPossible = after.FlushPossible && !before.FlushPossible
+	c.StraightPossible = after.StraightPossible && !before.StraightPossible
+	c.Overcard = true
+	for _, p := range prev {
+		if cards.Rank(p) >= cards.Rank(last) {
+			c.Overcard = false
+		}
+		if cards.Rank(p) == cards.Rank(last) {
+			c.Paired = true
+		}
+	}
+	return c
+}
+
+// Big reports whether the card changed the board a lot: it made a flush or
+// straight possible, paired the board or came above it.
+func (c Change) Big() bool {
+	return c.FlushPossible || c.StraightPossible || c.Paired || c.Overcard
+}
+
+// String lists the changes, e.g. "flush possible, overcard", or "no
+// change".
+func (c Change) String() string {
+	var s []string
+	for _, l := range []struct {
+		on   bool
+		name string
+	}{{c.FlushPossible, "flush possible"}, {c.StraightPossible, "straight possible"}, {c.Paired, "paired"}, {c.Overcard, "overcard"}} {
+		if l.on {
+			s = append(s, l.name)
+		}
+	}
+	if len(s) == 0 {
+		return "no change"
+	}
+	return strings.Join(s, ", ")
+}
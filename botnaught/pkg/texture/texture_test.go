@@ -0,0 +1,57 @@
+package texture
+
+import (
+	"testing"
+
+	cards "go-poker-project/Botnaught/botnaught/pkg/cards"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		board string
+		want  Texture
+		str   string
+	}{
+		{"Kd7c2h", Texture{}, "dry rainbow"},
+		{"Kd7d2h", Texture{Suits: TwoTone}, "dry two-tone"},
+		{"JhTh9c", Texture{Suits: TwoTone, Connected: true, High: true, StraightPossible: true, Wet: true},
+			"wet two-tone connected high, straight possible"},
+		{"8s5s2s", Texture{Suits: Monotone, Low: true, FlushPossible: true, Wet: true}, "wet monotone low, flush possible"},
+		{"7c7d2h", Texture{Paired: true, Low: true}, "dry rainbow paired low"},
+		{"Ah2c3d", Texture{Connected: true, StraightPossible: true, Wet: true}, "wet rainbow connected, straight possible"},
+		{"As", Texture{}, "dry rainbow"},
+	}
+	for _, tt := range tests {
+		got := Analyze(cards.MustParse(tt.board))
+		if got != tt.want {
+			t.Errorf("Analyze(%s) = %+v, want %+v", tt.board, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("Analyze(%s).String() = %q, want %q", tt.board, got.String(), tt.str)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		board string
+		want  Change
+	}{
+		{"Kd7c2h", Change{}},
+		{"Kd7c2h3s", Change{}},
+		{"Kd7c2hAs", Change{Overcard: true}},
+		{"Kd7c2h7s", Change{Paired: true}},
+		{"Kd7d2h4d", Change{FlushPossible: true}},
+		{"Kd7c2h6s", Change{}},
+		{"Kd7c5h6s", Change{StraightPossible: true}},
+	}
+	for _, tt := range tests {
+		got := Compare(cards.MustParse(tt.board))
+		if got != tt.want {
+			t.Errorf("Compare(%s) = %+v, want %+v", tt.board, got, tt.want)
+		}
+		if got.Big() != (tt.want != Change{}) {
+			t.Errorf("Compare(%s).Big() = %v", tt.board, got.Big())
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Package tune searches for Bet parameters with a genetic algorithm. Each
+// candidate is a point in the unit cube, one axis per BetParamSpec scaled to
+// its range, and its fitness is its win rate in duplicate arena matches
+// against fixed-rule opponents. All candidates of a generation are dealt the
+// same cards so that they are compared on equal terms.
+package tune
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	arena "go-poker-project/Botnaught/botnaught/pkg/arena"
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+// Config describes a search.
+type Config struct {
+	Generations int
+	Population  int
+	// Elite is how many of the fittest candidates survive unchanged into
+	// the next generation.
+	Elite int
+	// Mutation is the chance each parameter of a child is mutated and Sigma
+	// the standard deviation of a mutation, as a fraction of the range.
+	Mutation float64
+	Sigma    float64
+	Seed     int64
+	// Match is played against each opponent: Hands deals, each dealt once
+	// from either seat. Its Seed is replaced every generation.
+	Match     arena.Config
+	Opponents []service.BotDefinition
+	// Workers is how many candidates are evaluated at once; 0 means one per
+	// CPU.
+	Workers int
+}
+
+// DefaultConfig returns a search of 30 generations of 24 candidates, each
+// playing 500 deals against every default opponent.
+func DefaultConfig() Config {
+	match := arena.DefaultConfig()
+	match.Hands = 500
+	match.Duplicate = true
+	return Config{
+		Generations: 30,
+		Population:  24,
+		Elite:       4,
+		Mutation:    0.2,
+		Sigma:       0.1,
+		Seed:        1,
+		Match:       match,
+		Opponents:   DefaultOpponents(),
+	}
+}
+
+// DefaultOpponents returns one of each fixed-rule strategy worth beating.
+func DefaultOpponents() []service.BotDefinition {
+	return []service.BotDefinition{
+		{Name: "Caller", Strategy: "call"},
+		{Name: "TightPassive", Strategy: "tight-passive"},
+		{Name: "LooseAggressive", Strategy: "loose-aggressive"},
+		{Name: "Equity", Strategy: "equity", Params: map[string]float64{"seed": 1, "samples": 200}},
+	}
+}
+
+// Generation summarizes one generation of the search.
+type Generation struct {
+	Number int     `json:"number"`
+	Best   float64 `json:"best"`
+	Mean   float64 `json:"mean"`
+	// Params are the fittest candidate's.
+	Params service.BetParams `json:"params"`
+}
+
+// Result is the outcome of a search: the fittest candidate of the last
+// generation, its win rate in big blinds per 100 hands averaged over the
+// opponents, and the history of the search.
+type Result struct {
+	Best    service.BetParams `json:"best"`
+	Fitness float64           `json:"fitness"`
+	History []Generation      `json:"history"`
+}
+
+// candidate is a point in the search space and its fitness.
+type candidate struct {
+	genes   []float64
+	fitness float64
+}
+
+// Run searches from base, which is always in the first generation, calling
+// progress after every generation when it is not nil.
+func Run(ctx context.Context, cfg Config, base service.BetParams, progress func(Generation)) (Result, error) {
+	switch {
+	case cfg.Generations < 1 || cfg.Population < 2:
+		return Result{}, errors.New("tune: need at least one generation of two candidates")
+	case cfg.Elite < 0 || cfg.Elite >= cfg.Population:
+		return Result{}, errors.New("tune: elite must be smaller than the population")
+	case len(cfg.Opponents) == 0:
+		return Result{}, errors.New("tune: no opponents")
+	}
+	if err := base.Validate(); err != nil {
+		return Result{}, err
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	population := make([]candidate, cfg.Population)
+	population[0].genes = Encode(base)
+	for i := 1; i < len(population); i++ {
+		population[i].genes = make([]float64, len(service.BetParamSpecs))
+		for j := range population[i].genes {
+			population[i].genes[j] = rng.Float64()
+		}
+	}
+
+	var res Result
+	for gen := 0; gen < cfg.Generations; gen++ {
+		match := cfg.Match
+		match.Seed = cfg.Seed*1000003 + int64(gen)*int64(cfg.Match.Hands)
+		if err := evaluate(ctx, cfg, match, population); err != nil {
+			return Result{}, err
+		}
+		sort.SliceStable(population, func(i, j int) bool { return population[i].fitness > population[j].fitness })
+
+		g := Generation{Number: gen + 1, Best: population[0].fitness, Params: Decode(population[0].genes)}
+		for _, c := range population {
+			g.Mean += c.fitness / float64(len(population))
+		}
+		res.History = append(res.History, g)
+		res.Best, res.Fitness = g.Params, g.Best
+		if progress != nil {
+			progress(g)
+		}
+		if gen < cfg.Generations-1 {
+			population = breed(rng, cfg, population)
+		}
+	}
+	return res, nil
+}
+
+// breed returns the next generation: the elite, then children of parents
+// picked by tournament.
+func breed(rng *rand.Rand, cfg Config, population []candidate) []candidate {
+	next := make([]candidate, 0, len(population))
+	for _, c := range population[:cfg.Elite] {
+		next = append(next, candidate{genes: c.genes})
+	}
+	for len(next) < len(population) {
+		a, b := tournament(rng, population), tournament(rng, population)
+		child := make([]float64, len(a.genes))
+		for i := range child {
+			// Blend crossover: anywhere between the parents and a little
+			// beyond, so the search doesn't only shrink.
+			u := rng.Float64()*1.5 - 0.25
+			child[i] = a.genes[i] + u*(b.genes[i]-a.genes[i])
+			if rng.Float64() < cfg.Mutation {
+				child[i] += rng.NormFloat64() * cfg.Sigma
+			}
+			child[i] = clamp(child[i])
+		}
+		next = append(next, candidate{genes: child})
+	}
+	return next
+}
+
+// tournament returns the fitter of three candidates drawn at random.
+func tournament(rng *rand.Rand, population []candidate) candidate {
+	best := population[rng.Intn(len(population))]
+	for i := 0; i < 2; i++ {
+		if c := population[rng.Intn(len(population))]; c.fitness > best.fitness {
+			best = c
+		}
+	}
+	return best
+}
+
+// evaluate sets the fitness of every candidate, cfg.Workers at a time.
+func evaluate(ctx context.Context, cfg Config, match arena.Config, population []candidate) error {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan int)
+	errs := make(chan error, len(population))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fitness, err := Fitness(ctx, match, Decode(population[i].genes), cfg.Opponents)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				population[i].fitness = fitness
+			}
+		}()
+	}
+	for i := range population {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// Fitness is the win rate of a basic bot playing params, in big blinds per
+// 100 hands, averaged over matches against each of the opponents.
+func Fitness(ctx context.Context, match arena.Config, params service.BetParams, opponents []service.BotDefinition) (float64, error) {
+	total := 0.0
+	for _, def := range opponents {
+		entrants := make([]arena.Entrant, 0, 2)
+		for _, d := range []struct {
+			def  service.BotDefinition
+			base service.BetParams
+		}{
+			{service.BotDefinition{Name: "Candidate", Strategy: "basic"}, params},
+			{def, service.DefaultBetParams()},
+		} {
+			bot, err := service.NewBot(d.def, d.base, nil)
+			if err != nil {
+				return 0, err
+			}
+			if bot.Log != nil {
+				bot.Log.Discard()
+			}
+			if bot.Seeds != nil {
+				// Mixed strategies play the same way for the same match
+				bot.Seeds.Reset(match.Seed)
+			}
+			entrants = append(entrants, arena.Entrant{Name: d.def.Name, Bot: bot.Service})
+		}
+		report, err := arena.Run(ctx, match, entrants)
+		if err != nil {
+			return 0, err
+		}
+		total += report.Bots[0].BBPer100
+	}
+	return total / float64(len(opponents)), nil
+}
+
+// Encode maps p into the unit cube.
+func Encode(p service.BetParams) []float64 {
+	genes := make([]float64, len(service.BetParamSpecs))
+	for i, spec := range service.BetParamSpecs {
+		genes[i] = clamp((*spec.Field(&p) - spec.Min) / (spec.Max - spec.Min))
+	}
+	return genes
+}
+
+// Decode maps a point of the unit cube back to parameters.
+func Decode(genes []float64) service.BetParams {
+	var p service.BetParams
+	for i, spec := range service.BetParamSpecs {
+		*spec.Field(&p) = spec.Min + clamp(genes[i])*(spec.Max-spec.Min)
+	}
+	return p
+}
+
+func clamp(x float64) float64 {
+	switch {
+	case x < 0:
+		return 0
+	case x > 1:
+		return 1
+	}
+	return x
+}
@@ -0,0 +1,48 @@
+package tune
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	service "go-poker-project/Botnaught/botnaught/pkg/service"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	p := service.DefaultBetParams()
+	if got := Decode(Encode(p)); got.Validate() != nil || math.Abs(got.CallRankCutoff-p.CallRankCutoff) > 1e-6 || math.Abs(got.PreflopRaise-p.PreflopRaise) > 1e-9 {
+		t.Errorf("Decode(Encode(%+v)) = %+v", p, got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Generations, cfg.Population, cfg.Elite = 3, 6, 2
+	cfg.Match.Hands = 30
+	cfg.Opponents = []service.BotDefinition{{Name: "Caller", Strategy: "call"}}
+	var gens []Generation
+	res, err := Run(context.Background(), cfg, service.DefaultBetParams(), func(g Generation) { gens = append(gens, g) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gens) != 3 || len(res.History) != 3 {
+		t.Fatalf("reported %d generations, history of %d; want 3", len(gens), len(res.History))
+	}
+	if err := res.Best.Validate(); err != nil {
+		t.Error(err)
+	}
+	for _, g := range gens {
+		if g.Best < g.Mean {
+			t.Errorf("generation %d: best %v below mean %v", g.Number, g.Best, g.Mean)
+		}
+	}
+
+	// The same seed finds the same parameters.
+	again, err := Run(context.Background(), cfg, service.DefaultBetParams(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Best != res.Best {
+		t.Errorf("rerun found %+v, first run %+v", again.Best, res.Best)
+	}
+}